@@ -2,6 +2,8 @@ package ast
 
 import (
 	"bytes"
+	"sort"
+	"strings"
 
 	"local.packages/token"
 )
@@ -10,6 +12,8 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	// Pos ノードのソース上の範囲を返す。
+	Pos() (start, end Position)
 }
 
 // Statement 文
@@ -24,8 +28,32 @@ type Expression interface {
 	expressionNode() // 式と文を間違えていたらコンパイラが教えてくれる
 }
 
+// Position ソース上の位置
+type Position struct {
+	Line   int // 行番号（1始まり）
+	Column int // 列番号（1始まり）
+}
+
+// Span ノードのソース上の範囲
+// 各ノードに埋め込み、構文解析器が範囲を記録する。位置情報が無い場合はゼロ値。
+type Span struct {
+	StartPos Position // ノードの最初の文字の位置
+	EndPos   Position // ノードの最後の文字の次の位置
+}
+
+// Pos ノードのソース上の範囲を返す。
+func (s *Span) Pos() (start, end Position) {
+	return s.StartPos, s.EndPos
+}
+
+// SetPos ノードのソース上の範囲を記録する。
+func (s *Span) SetPos(start, end Position) {
+	s.StartPos, s.EndPos = start, end
+}
+
 // Program すべてのASTのルートノード
 type Program struct {
+	Span
 	// Monkeyプログラムの文の集まりが格納される。
 	Statements []Statement
 }
@@ -50,6 +78,7 @@ func (p *Program) String() string {
 
 // LetStatement let文
 type LetStatement struct {
+	Span
 	Token token.Token // token.LET
 	Name  *Identifier // 束縛の識別子を保持する
 	Value Expression  // 値を保持する式を保持する
@@ -66,9 +95,9 @@ func (ls *LetStatement) String() string {
 
 	out.WriteString(ls.TokenLiteral() + " ")
 	out.WriteString(ls.Name.String())
-	out.WriteString(" = ")
 
 	if ls.Value != nil {
+		out.WriteString(" = ")
 		out.WriteString(ls.Value.String())
 	}
 
@@ -77,8 +106,110 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+// ConstStatement 再代入できない束縛を作るconst文
+type ConstStatement struct {
+	Span
+	Token token.Token // token.CONST
+	Name  *Identifier // 束縛の識別子を保持する
+	Value Expression  // 値を保持する式を保持する
+}
+
+func (cs *ConstStatement) statementNode() {}
+
+func (cs *ConstStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+
+func (cs *ConstStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(cs.TokenLiteral() + " ")
+	out.WriteString(cs.Name.String())
+	out.WriteString(" = ")
+	out.WriteString(cs.Value.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+
+// DestructuringLetStatement 分割代入のlet文
+// "let [a, b] = arr;"は配列の要素を先頭から順に、"let {x, y} = h;"はハッシュの同名の文字列キーの値を、
+// それぞれの識別子に束縛する。
+type DestructuringLetStatement struct {
+	Span
+	Token   token.Token // token.LET
+	Pattern token.Token // パターンを開く'['か'{'
+	Names   []*Identifier
+	Value   Expression
+}
+
+func (ds *DestructuringLetStatement) statementNode() {}
+
+func (ds *DestructuringLetStatement) TokenLiteral() string {
+	return ds.Token.Literal
+}
+
+// IsHash ハッシュの分割代入であればtrueを返す。
+func (ds *DestructuringLetStatement) IsHash() bool {
+	return ds.Pattern.Type == token.LBRACE
+}
+
+func (ds *DestructuringLetStatement) String() string {
+	var out bytes.Buffer
+
+	names := []string{}
+	for _, n := range ds.Names {
+		names = append(names, n.String())
+	}
+
+	open, close := "[", "]"
+	if ds.IsHash() {
+		open, close = "{", "}"
+	}
+
+	out.WriteString(ds.TokenLiteral() + " ")
+	out.WriteString(open)
+	out.WriteString(strings.Join(names, ", "))
+	out.WriteString(close)
+	out.WriteString(" = ")
+	out.WriteString(ds.Value.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+
+// AssignStatement 既存の束縛への再代入文
+type AssignStatement struct {
+	Span
+	Token token.Token // '=' トークン、または '+=' などの複合代入演算子のトークン
+	Name  *Identifier // 再代入する束縛の識別子を保持する
+	Value Expression  // 新しい値を保持する式を保持する
+}
+
+func (as *AssignStatement) statementNode() {}
+
+func (as *AssignStatement) TokenLiteral() string {
+	return as.Token.Literal
+}
+
+func (as *AssignStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(as.Name.String())
+	out.WriteString(" = ")
+
+	if as.Value != nil {
+		out.WriteString(as.Value.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+
 // Identifier 識別子
 type Identifier struct {
+	Span
 	Token token.Token // token.IDENT
 	Value string
 }
@@ -95,6 +226,7 @@ func (i *Identifier) String() string {
 
 // ReturnStatement return文
 type ReturnStatement struct {
+	Span
 	Token       token.Token // 'return' トークン
 	ReturnValue Expression
 }
@@ -121,6 +253,7 @@ func (rs *ReturnStatement) String() string {
 
 // ExpressionStatement 式文
 type ExpressionStatement struct {
+	Span
 	Token      token.Token // 式の最初のトークン
 	Expression Expression
 }
@@ -138,8 +271,127 @@ func (es *ExpressionStatement) String() string {
 	return ""
 }
 
+// BlockStatement ブロック文
+type BlockStatement struct {
+	Span
+	Token      token.Token // '{' トークン
+	Statements []Statement
+}
+
+func (bs *BlockStatement) statementNode() {}
+
+func (bs *BlockStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+
+func (bs *BlockStatement) String() string {
+	var out bytes.Buffer
+
+	for _, s := range bs.Statements {
+		out.WriteString(s.String())
+	}
+
+	return out.String()
+}
+
+// WhileStatement while文
+type WhileStatement struct {
+	Span
+	Token     token.Token // 'while' トークン
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+
+func (ws *WhileStatement) TokenLiteral() string {
+	return ws.Token.Literal
+}
+
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while ")
+	out.WriteString(parenthesized(ws.Condition))
+	out.WriteString(" ")
+	out.WriteString(bracedBlock(ws.Body))
+
+	return out.String()
+}
+
+// ForStatement for文
+// Init、Condition、Postはいずれも省略できる。
+type ForStatement struct {
+	Span
+	Token     token.Token // 'for' トークン
+	Init      Statement   // 初期化文
+	Condition Expression  // 条件式
+	Post      Statement   // 各反復の後に実行する文
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode() {}
+
+func (fs *ForStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	if fs.Init != nil {
+		out.WriteString(strings.TrimSuffix(fs.Init.String(), ";"))
+	}
+	out.WriteString("; ")
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString("; ")
+	if fs.Post != nil {
+		out.WriteString(strings.TrimSuffix(fs.Post.String(), ";"))
+	}
+	out.WriteString(") ")
+	out.WriteString(bracedBlock(fs.Body))
+
+	return out.String()
+}
+
+// BreakStatement break文
+type BreakStatement struct {
+	Span
+	Token token.Token // 'break' トークン
+}
+
+func (bs *BreakStatement) statementNode() {}
+
+func (bs *BreakStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+
+func (bs *BreakStatement) String() string {
+	return bs.TokenLiteral() + ";"
+}
+
+// ContinueStatement continue文
+type ContinueStatement struct {
+	Span
+	Token token.Token // 'continue' トークン
+}
+
+func (cs *ContinueStatement) statementNode() {}
+
+func (cs *ContinueStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+
+func (cs *ContinueStatement) String() string {
+	return cs.TokenLiteral() + ";"
+}
+
 // IntegerLiteral 整数リテラル
 type IntegerLiteral struct {
+	Span
 	Token token.Token
 	Value int64
 }
@@ -153,3 +405,635 @@ func (il *IntegerLiteral) TokenLiteral() string {
 func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
+
+// FloatLiteral 浮動小数点数リテラル
+type FloatLiteral struct {
+	Span
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() {}
+
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
+// StringLiteral 文字列リテラル
+type StringLiteral struct {
+	Span
+	Token token.Token
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode() {}
+
+func (sl *StringLiteral) TokenLiteral() string {
+	return sl.Token.Literal
+}
+
+func (sl *StringLiteral) String() string {
+	return sl.Token.Literal
+}
+
+// TemplateLiteral テンプレート文字列リテラル
+// "`a${x}b${y}c`"は、Stringsが["a", "b", "c"]、Expressionsが[x, y]になる。
+// Stringsは常にExpressionsより1つ多く、Strings[i]、Expressions[i]、Strings[i+1]の順に連結する。
+type TemplateLiteral struct {
+	Span
+	Token       token.Token // '`' トークン
+	Strings     []string
+	Expressions []Expression
+}
+
+func (tl *TemplateLiteral) expressionNode() {}
+
+func (tl *TemplateLiteral) TokenLiteral() string {
+	return tl.Token.Literal
+}
+
+func (tl *TemplateLiteral) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("`")
+	for i, s := range tl.Strings {
+		out.WriteString(s)
+		if i < len(tl.Expressions) {
+			out.WriteString("${")
+			out.WriteString(tl.Expressions[i].String())
+			out.WriteString("}")
+		}
+	}
+	out.WriteString("`")
+
+	return out.String()
+}
+
+// NullLiteral nullリテラル
+type NullLiteral struct {
+	Span
+	Token token.Token
+}
+
+func (nl *NullLiteral) expressionNode() {}
+
+func (nl *NullLiteral) TokenLiteral() string {
+	return nl.Token.Literal
+}
+
+func (nl *NullLiteral) String() string {
+	return nl.Token.Literal
+}
+
+// Boolean 真偽値リテラル
+type Boolean struct {
+	Span
+	Token token.Token
+	Value bool
+}
+
+func (b *Boolean) expressionNode() {}
+
+func (b *Boolean) TokenLiteral() string {
+	return b.Token.Literal
+}
+
+func (b *Boolean) String() string {
+	return b.Token.Literal
+}
+
+// PrefixExpression 前置式
+// "-5"のような負の数も、整数リテラルにマイナスの前置演算子を適用した式として表す。
+// "!"は真偽値としての否定、"~"は整数のビット反転を表す。
+type PrefixExpression struct {
+	Span
+	Token    token.Token // 前置演算子のトークン
+	Operator string
+	Right    Expression
+}
+
+func (pe *PrefixExpression) expressionNode() {}
+
+func (pe *PrefixExpression) TokenLiteral() string {
+	return pe.Token.Literal
+}
+
+func (pe *PrefixExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(pe.Operator)
+	out.WriteString(pe.Right.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// InfixExpression 中置式
+type InfixExpression struct {
+	Span
+	Token    token.Token // 演算子のトークン
+	Left     Expression
+	Operator string
+	Right    Expression
+}
+
+func (ie *InfixExpression) expressionNode() {}
+
+func (ie *InfixExpression) TokenLiteral() string {
+	return ie.Token.Literal
+}
+
+func (ie *InfixExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString(" " + ie.Operator + " ")
+	out.WriteString(ie.Right.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// IfExpression if式
+// "else if"は、Alternativeのブロックが入れ子のIfExpressionだけを持つ形で表す。
+// このブロックは'{'ではなく'if'トークンを持つ。
+type IfExpression struct {
+	Span
+	Token       token.Token // 'if' トークン
+	Condition   Expression
+	Consequence *BlockStatement
+	Alternative *BlockStatement
+}
+
+func (ie *IfExpression) expressionNode() {}
+
+func (ie *IfExpression) TokenLiteral() string {
+	return ie.Token.Literal
+}
+
+// "if (cond) { ... } else { ... }"の形で返す。"else if"は入れ子の波括弧を付けずに続ける。
+func (ie *IfExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("if ")
+	out.WriteString(parenthesized(ie.Condition))
+	out.WriteString(" ")
+	out.WriteString(bracedBlock(ie.Consequence))
+
+	if elseIf, ok := ie.ElseIf(); ok {
+		out.WriteString(" else ")
+		out.WriteString(elseIf.String())
+	} else if ie.Alternative != nil {
+		out.WriteString(" else ")
+		out.WriteString(bracedBlock(ie.Alternative))
+	}
+
+	return out.String()
+}
+
+// ブロック文を波括弧で囲んだ文字列を返す。
+// BlockStatement.Stringは波括弧を含まないため、波括弧が必要な呼び出し側で囲む。
+func bracedBlock(bs *BlockStatement) string {
+	if len(bs.Statements) == 0 {
+		return "{}"
+	}
+
+	return "{ " + joinStatements(bs) + " }"
+}
+
+// ブロック文の文を"; "で区切って並べた文字列を返す。
+// let文などは自身の文字列の末尾にセミコロンを含むため、重ねないように取り除いてから区切る。
+// while文やfor文などの波括弧で終わる文の後にはセミコロンを書けないため、空白だけで区切る。
+func joinStatements(bs *BlockStatement) string {
+	var out bytes.Buffer
+
+	for i, s := range bs.Statements {
+		if i > 0 {
+			switch bs.Statements[i-1].(type) {
+			case *WhileStatement, *ForStatement:
+				out.WriteString(" ")
+			default:
+				out.WriteString("; ")
+			}
+		}
+		out.WriteString(strings.TrimSpace(strings.TrimSuffix(s.String(), ";")))
+	}
+
+	return out.String()
+}
+
+// 条件式などを括弧で囲んだ文字列を返す。
+// 中置式と前置式は自身を括弧で囲んで文字列にするため、括弧を重ねない。
+func parenthesized(exp Expression) string {
+	switch exp.(type) {
+	case *InfixExpression, *PrefixExpression:
+		return exp.String()
+	default:
+		return "(" + exp.String() + ")"
+	}
+}
+
+// ElseIf Alternativeが"else if"であれば、入れ子のIfExpressionを返す。
+func (ie *IfExpression) ElseIf() (*IfExpression, bool) {
+	if ie.Alternative == nil || ie.Alternative.Token.Type != token.IF || len(ie.Alternative.Statements) != 1 {
+		return nil, false
+	}
+	stmt, ok := ie.Alternative.Statements[0].(*ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+	elseIf, ok := stmt.Expression.(*IfExpression)
+	return elseIf, ok
+}
+
+// SwitchExpression switch式
+// 上から順にSubjectと等しい値を持つcaseを探し、そのBodyだけを評価する（フォールスルーしない）。
+// どのcaseにも一致しなければDefaultを評価する。
+type SwitchExpression struct {
+	Span
+	Token   token.Token // 'switch' トークン
+	Subject Expression
+	Cases   []*SwitchCase
+	Default *BlockStatement // defaultが無ければnil
+}
+
+// SwitchCase switch式のcase
+// "case 1, 2:"のように、複数の値をカンマで区切って並べられる。
+type SwitchCase struct {
+	Token  token.Token // 'case' トークン
+	Values []Expression
+	Body   *BlockStatement
+}
+
+func (se *SwitchExpression) expressionNode() {}
+
+func (se *SwitchExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+func (se *SwitchExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("switch ")
+	out.WriteString(parenthesized(se.Subject))
+	out.WriteString(" {")
+
+	for _, c := range se.Cases {
+		values := []string{}
+		for _, v := range c.Values {
+			values = append(values, v.String())
+		}
+
+		out.WriteString(" case ")
+		out.WriteString(strings.Join(values, ", "))
+		out.WriteString(":")
+		out.WriteString(caseBody(c.Body))
+	}
+
+	if se.Default != nil {
+		out.WriteString(" default:")
+		out.WriteString(caseBody(se.Default))
+	}
+
+	if len(se.Cases) != 0 || se.Default != nil {
+		out.WriteString(" ")
+	}
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// caseとdefaultの後に続く文を、先頭に空白を付けた文字列で返す。文が無ければ空文字列を返す。
+func caseBody(bs *BlockStatement) string {
+	if len(bs.Statements) == 0 {
+		return ""
+	}
+
+	return " " + joinStatements(bs)
+}
+
+// TryExpression try式
+// Bodyの評価中にエラーが発生した場合は、CatchParamにエラーを束縛してHandlerを評価し、その値を式の値とする。
+// エラーが発生しなければBodyの値を式の値とし、Handlerは評価しない。
+type TryExpression struct {
+	Span
+	Token      token.Token // 'try' トークン
+	Body       *BlockStatement
+	CatchParam *Identifier
+	Handler    *BlockStatement
+}
+
+func (te *TryExpression) expressionNode() {}
+
+func (te *TryExpression) TokenLiteral() string {
+	return te.Token.Literal
+}
+
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("try ")
+	out.WriteString(bracedBlock(te.Body))
+	out.WriteString(" catch (")
+	out.WriteString(te.CatchParam.String())
+	out.WriteString(") ")
+	out.WriteString(bracedBlock(te.Handler))
+
+	return out.String()
+}
+
+// HashLiteral ハッシュリテラル
+// "...h"のスプレッド要素は、キーを*SpreadExpression、値をnilとしてPairsに持つ。
+type HashLiteral struct {
+	Span
+	Token token.Token // '{' トークン
+	Pairs map[Expression]Expression
+}
+
+func (hl *HashLiteral) expressionNode() {}
+
+func (hl *HashLiteral) TokenLiteral() string {
+	return hl.Token.Literal
+}
+
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for key, value := range hl.Pairs {
+		if value == nil {
+			pairs = append(pairs, key.String())
+			continue
+		}
+		pairs = append(pairs, key.String()+":"+value.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// OrderedKeys キーをソース上の位置の順に並べて返す。
+// 位置情報が無い場合や同じ位置の場合は、キーの文字列表現の順とする。
+func (hl *HashLiteral) OrderedKeys() []Expression {
+	keys := make([]Expression, 0, len(hl.Pairs))
+	for key := range hl.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := keys[i].Pos()
+		b, _ := keys[j].Pos()
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+// IndexExpression 添字式
+type IndexExpression struct {
+	Span
+	Token token.Token // '[' トークン
+	Left  Expression  // 添字でアクセスされる式
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode() {}
+
+func (ie *IndexExpression) TokenLiteral() string {
+	return ie.Token.Literal
+}
+
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// SliceExpression スライス式
+// 開始位置と終了位置は省略でき、省略した場合はnilになる。
+type SliceExpression struct {
+	Span
+	Token token.Token // '[' トークン
+	Left  Expression  // 部分を取り出される式
+	Start Expression
+	End   Expression
+}
+
+func (se *SliceExpression) expressionNode() {}
+
+func (se *SliceExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// SpreadExpression 配列やハッシュのリテラルの中で、既存のコレクションを展開する"...xs"の要素
+type SpreadExpression struct {
+	Span
+	Token token.Token // '...' トークン
+	Value Expression  // 展開するコレクションの式
+}
+
+func (se *SpreadExpression) expressionNode() {}
+
+func (se *SpreadExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+func (se *SpreadExpression) String() string {
+	return "..." + se.Value.String()
+}
+
+// ArrayLiteral 配列リテラル
+type ArrayLiteral struct {
+	Span
+	Token    token.Token // '[' トークン
+	Elements []Expression
+}
+
+func (al *ArrayLiteral) expressionNode() {}
+
+func (al *ArrayLiteral) TokenLiteral() string {
+	return al.Token.Literal
+}
+
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range al.Elements {
+		elements = append(elements, el.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// TupleLiteral 複数の値の組
+// "return a, b;"のようにreturn文で複数の値を返す場合に、返す値を並べて持つ。
+type TupleLiteral struct {
+	Span
+	Token    token.Token // 最初の値の後の ',' トークン
+	Elements []Expression
+}
+
+func (tl *TupleLiteral) expressionNode() {}
+
+func (tl *TupleLiteral) TokenLiteral() string {
+	return tl.Token.Literal
+}
+
+func (tl *TupleLiteral) String() string {
+	elements := []string{}
+	for _, el := range tl.Elements {
+		elements = append(elements, el.String())
+	}
+
+	return strings.Join(elements, ", ")
+}
+
+// FunctionLiteral 関数リテラル
+// "fn fact(n) { ... }"のように名前を付けると、関数の本体からその名前で自身を参照できる。
+type FunctionLiteral struct {
+	Span
+	Token      token.Token // 'fn' トークン
+	Name       *Identifier // 関数の名前。無名関数であればnil。
+	Parameters []*Identifier
+	// 仮引数の名前とデフォルト値の式のマッピング。デフォルト値を持つ仮引数が無ければnil。
+	Defaults map[string]Expression
+	// 残りの引数を配列として受け取る仮引数。無ければnil。
+	Rest *Identifier
+	Body *BlockStatement
+}
+
+func (fl *FunctionLiteral) expressionNode() {}
+
+func (fl *FunctionLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
+func (fl *FunctionLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range fl.Parameters {
+		if value, ok := fl.Defaults[p.Value]; ok {
+			params = append(params, p.String()+" = "+value.String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if fl.Rest != nil {
+		params = append(params, "..."+fl.Rest.String())
+	}
+
+	out.WriteString(fl.TokenLiteral())
+	if fl.Name != nil {
+		out.WriteString(" " + fl.Name.String())
+	}
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(bracedBlock(fl.Body))
+
+	return out.String()
+}
+
+// CallExpression 関数呼び出し式
+type CallExpression struct {
+	Span
+	Token     token.Token // '(' トークン
+	Function  Expression  // 識別子か関数リテラル
+	Arguments []Expression
+}
+
+func (ce *CallExpression) expressionNode() {}
+
+func (ce *CallExpression) TokenLiteral() string {
+	return ce.Token.Literal
+}
+
+func (ce *CallExpression) String() string {
+	var out bytes.Buffer
+
+	args := []string{}
+	for _, a := range ce.Arguments {
+		args = append(args, a.String())
+	}
+
+	out.WriteString(ce.Function.String())
+	out.WriteString("(")
+	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// MacroLiteral マクロリテラル
+type MacroLiteral struct {
+	Span
+	Token      token.Token // 'macro' トークン
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (ml *MacroLiteral) expressionNode() {}
+
+func (ml *MacroLiteral) TokenLiteral() string {
+	return ml.Token.Literal
+}
+
+func (ml *MacroLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range ml.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(ml.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(bracedBlock(ml.Body))
+
+	return out.String()
+}
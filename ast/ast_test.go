@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"encoding/json"
 	"testing"
 
 	"local.packages/token"
@@ -27,3 +28,180 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. got=%q", program.String())
 	}
 }
+
+func TestMarshalJSON(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "x"},
+					Value: "x",
+				},
+				Value: &InfixExpression{
+					Token: token.Token{Type: token.PLUS, Literal: "+"},
+					Left: &IndexExpression{
+						Token: token.Token{Type: token.LBRACKET, Literal: "["},
+						Left: &ArrayLiteral{
+							Token: token.Token{Type: token.LBRACKET, Literal: "["},
+							Elements: []Expression{
+								&IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+								&StringLiteral{Token: token.Token{Type: token.STRING, Literal: "a"}, Value: "a"},
+							},
+						},
+						Index: &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "0"}, Value: 0},
+					},
+					Operator: "+",
+					Right:    &FloatLiteral{Token: token.Token{Type: token.FLOAT, Literal: "1.5"}, Value: 1.5},
+				},
+			},
+			&WhileStatement{
+				Token:     token.Token{Type: token.WHILE, Literal: "while"},
+				Condition: &Identifier{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"},
+				Body: &BlockStatement{
+					Token: token.Token{Type: token.LBRACE, Literal: "{"},
+					Statements: []Statement{
+						&BreakStatement{Token: token.Token{Type: token.BREAK, Literal: "break"}},
+					},
+				},
+			},
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "y"},
+					Value: "y",
+				},
+			},
+		},
+	}
+
+	expected := `{"nodeType":"Program","statements":[` +
+		`{"nodeType":"LetStatement","name":{"nodeType":"Identifier","value":"x"},"value":` +
+		`{"nodeType":"InfixExpression","operator":"+","left":` +
+		`{"nodeType":"IndexExpression","left":{"nodeType":"ArrayLiteral","elements":[` +
+		`{"nodeType":"IntegerLiteral","value":1},{"nodeType":"StringLiteral","value":"a"}]},` +
+		`"index":{"nodeType":"IntegerLiteral","value":0}},` +
+		`"right":{"nodeType":"FloatLiteral","value":1.5}}},` +
+		`{"nodeType":"WhileStatement","condition":{"nodeType":"Identifier","value":"x"},` +
+		`"body":{"nodeType":"BlockStatement","statements":[{"nodeType":"BreakStatement"}]}},` +
+		`{"nodeType":"LetStatement","name":{"nodeType":"Identifier","value":"y"},"value":null}]}`
+
+	actual, err := json.Marshal(program)
+	if err != nil {
+		t.Fatalf("json.Marshal(program) returned error: %s", err)
+	}
+
+	if string(actual) != expected {
+		t.Errorf("json.Marshal(program) wrong.\nexpected=%s\ngot=%s", expected, actual)
+	}
+}
+
+func TestMarshalJSONHashLiteral(t *testing.T) {
+	hash := &HashLiteral{
+		Token: token.Token{Type: token.LBRACE, Literal: "{"},
+		Pairs: map[Expression]Expression{
+			&StringLiteral{Token: token.Token{Type: token.STRING, Literal: "b"}, Value: "b"}: &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "2"}, Value: 2},
+			&StringLiteral{Token: token.Token{Type: token.STRING, Literal: "a"}, Value: "a"}: &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+		},
+	}
+
+	expected := `{"nodeType":"HashLiteral","pairs":[` +
+		`{"key":{"nodeType":"StringLiteral","value":"a"},"value":{"nodeType":"IntegerLiteral","value":1}},` +
+		`{"key":{"nodeType":"StringLiteral","value":"b"},"value":{"nodeType":"IntegerLiteral","value":2}}]}`
+
+	actual, err := json.Marshal(hash)
+	if err != nil {
+		t.Fatalf("json.Marshal(hash) returned error: %s", err)
+	}
+
+	if string(actual) != expected {
+		t.Errorf("json.Marshal(hash) wrong.\nexpected=%s\ngot=%s", expected, actual)
+	}
+}
+
+// identifierCollector 訪問したIdentifierの名前を順に集めるVisitor
+type identifierCollector struct {
+	names []string
+}
+
+func (c *identifierCollector) Visit(node Node) Visitor {
+	if ident, ok := node.(*Identifier); ok {
+		c.names = append(c.names, ident.Value)
+	}
+	return c
+}
+
+func TestWalkCollectIdentifiers(t *testing.T) {
+	ident := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+
+	// let x = a + b;
+	// while (x) { y = arr[i]; }
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  ident("x"),
+				Value: &InfixExpression{
+					Token:    token.Token{Type: token.PLUS, Literal: "+"},
+					Left:     ident("a"),
+					Operator: "+",
+					Right:    ident("b"),
+				},
+			},
+			&WhileStatement{
+				Token:     token.Token{Type: token.WHILE, Literal: "while"},
+				Condition: ident("x"),
+				Body: &BlockStatement{
+					Token: token.Token{Type: token.LBRACE, Literal: "{"},
+					Statements: []Statement{
+						&AssignStatement{
+							Token: token.Token{Type: token.ASSIGN, Literal: "="},
+							Name:  ident("y"),
+							Value: &IndexExpression{
+								Token: token.Token{Type: token.LBRACKET, Literal: "["},
+								Left:  ident("arr"),
+								Index: ident("i"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	collector := &identifierCollector{}
+	Walk(program, collector)
+
+	expected := []string{"x", "a", "b", "x", "y", "arr", "i"}
+	if len(collector.names) != len(expected) {
+		t.Fatalf("wrong number of identifiers. expected=%v, got=%v", expected, collector.names)
+	}
+
+	for i, name := range expected {
+		if collector.names[i] != name {
+			t.Errorf("names[%d] wrong. expected=%q, got=%q", i, name, collector.names[i])
+		}
+	}
+}
+
+func TestWalkNilChildren(t *testing.T) {
+	tests := []Node{
+		&LetStatement{Token: token.Token{Type: token.LET, Literal: "let"}},
+		&ReturnStatement{Token: token.Token{Type: token.RETURN, Literal: "return"}},
+		&WhileStatement{Token: token.Token{Type: token.WHILE, Literal: "while"}},
+		&ForStatement{Token: token.Token{Type: token.FOR, Literal: "for"}},
+		&BlockStatement{Token: token.Token{Type: token.LBRACE, Literal: "{"}},
+		&ArrayLiteral{Token: token.Token{Type: token.LBRACKET, Literal: "["}},
+	}
+
+	for _, node := range tests {
+		collector := &identifierCollector{}
+		Walk(node, collector)
+
+		if len(collector.names) != 0 {
+			t.Errorf("Walk(%T) collected identifiers. got=%v", node, collector.names)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+)
+
+// 整形時のインデントの単位
+const formatIndent = "  "
+
+// 中置演算子の優先順位。値が大きいほど強く結合する。
+// 構文解析器の優先順位と同じ順に並べる。
+var formatPrecedences = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3,
+	"!=": 3,
+	"<":  4,
+	">":  4,
+	"|":  5,
+	"^":  6,
+	"&":  7,
+	"+":  8,
+	"-":  8,
+	"<<": 9,
+	">>": 9,
+	"*":  10,
+	"/":  10,
+	"%":  10,
+}
+
+// 前置式と、関数呼び出し・添字などの後置の式の優先順位
+const (
+	formatPrefixPrecedence  = 11
+	formatPostfixPrecedence = 12
+	formatPrimaryPrecedence = 13
+)
+
+// 優先順位の分からない中置演算子を表す。
+// RegisterInfixOperatorで追加された演算子の優先順位はASTからは分からないため、
+// 被演算子になる場合も、被演算子に中置式や前置式を持つ場合も括弧で囲む。
+const formatUnknownPrecedence = 0
+
+// Format 整形したソースコードを返す。
+// 文は1行に1つ書き、ブロックの中は2スペースでインデントする。演算子の前後には空白を置く。
+// 括弧は演算子の優先順位のとおりに結合しない場合にだけ付ける。
+// 元のソースで文の間に空行があった場合は、空行を1つだけ残す。
+// 整形した結果を構文解析して再び整形しても、結果は変わらない。
+// コメントはASTに残らないため、整形した結果には含まれない。
+func (p *Program) Format() string {
+	f := &formatter{}
+	f.statements(p.Statements)
+	return f.out.String()
+}
+
+// ASTをソースコードとして書き出す。
+type formatter struct {
+	out bytes.Buffer
+	// 現在のインデントの深さ
+	depth int
+}
+
+// 文を1行ずつ、現在のインデントで書き出す。
+func (f *formatter) statements(stmts []Statement) {
+	for i, s := range stmts {
+		var next Statement
+		if i+1 < len(stmts) {
+			next = stmts[i+1]
+		}
+
+		if i > 0 && hasBlankLineBetween(stmts[i-1], s) {
+			f.out.WriteString("\n")
+		}
+
+		f.indent()
+		f.statement(s, next)
+		f.out.WriteString("\n")
+	}
+}
+
+// 元のソースで2つの文の間に空行があればtrueを返す。
+func hasBlankLineBetween(prev, next Statement) bool {
+	_, end := prev.Pos()
+	start, _ := next.Pos()
+	return end.Line != 0 && start.Line > end.Line+1
+}
+
+func (f *formatter) indent() {
+	f.out.WriteString(strings.Repeat(formatIndent, f.depth))
+}
+
+// 文を書き出す。nextは同じブロックの次の文で、最後の文であればnil。
+func (f *formatter) statement(s Statement, next Statement) {
+	switch s := s.(type) {
+	case *LetStatement:
+		f.out.WriteString("let ")
+		f.out.WriteString(s.Name.Value)
+		if s.Value != nil {
+			f.out.WriteString(" = ")
+			f.expression(s.Value)
+		}
+		f.out.WriteString(";")
+	case *ConstStatement:
+		f.out.WriteString("const ")
+		f.out.WriteString(s.Name.Value)
+		f.out.WriteString(" = ")
+		f.expression(s.Value)
+		f.out.WriteString(";")
+	case *DestructuringLetStatement:
+		names := []string{}
+		for _, n := range s.Names {
+			names = append(names, n.Value)
+		}
+		open, close := "[", "]"
+		if s.IsHash() {
+			open, close = "{", "}"
+		}
+		f.out.WriteString("let " + open + strings.Join(names, ", ") + close + " = ")
+		f.expression(s.Value)
+		f.out.WriteString(";")
+	case *AssignStatement:
+		f.out.WriteString(s.Name.Value)
+		f.out.WriteString(" = ")
+		f.expression(s.Value)
+		f.out.WriteString(";")
+	case *ReturnStatement:
+		f.out.WriteString("return")
+		if s.ReturnValue != nil {
+			f.out.WriteString(" ")
+			f.expression(s.ReturnValue)
+		}
+		f.out.WriteString(";")
+	case *ExpressionStatement:
+		f.expression(s.Expression)
+		if needsSemicolon(s, next) {
+			f.out.WriteString(";")
+		}
+	case *BlockStatement:
+		f.block(s)
+	case *WhileStatement:
+		f.out.WriteString("while (")
+		f.expression(s.Condition)
+		f.out.WriteString(") ")
+		f.block(s.Body)
+	case *ForStatement:
+		f.out.WriteString("for (")
+		if s.Init != nil {
+			f.out.WriteString(strings.TrimSuffix(formatStatement(s.Init, f.depth), ";"))
+		}
+		f.out.WriteString("; ")
+		if s.Condition != nil {
+			f.expression(s.Condition)
+		}
+		f.out.WriteString("; ")
+		if s.Post != nil {
+			f.out.WriteString(strings.TrimSuffix(formatStatement(s.Post, f.depth), ";"))
+		}
+		f.out.WriteString(") ")
+		f.block(s.Body)
+	case *BreakStatement:
+		f.out.WriteString("break;")
+	case *ContinueStatement:
+		f.out.WriteString("continue;")
+	}
+}
+
+// 文を単独で整形した文字列を返す。
+func formatStatement(s Statement, depth int) string {
+	f := &formatter{depth: depth}
+	f.statement(s, nil)
+	return f.out.String()
+}
+
+// 式文の後に';'が必要であればtrueを返す。
+// ブロックで終わるif式・switch式・try式の後には基本的に付けないが、
+// 次の文が'('、'['、'-'で始まる場合は、中置演算子や関数呼び出しとして続けて解析されないよう付ける。
+func needsSemicolon(s *ExpressionStatement, next Statement) bool {
+	switch s.Expression.(type) {
+	case *IfExpression, *SwitchExpression, *TryExpression:
+	default:
+		return true
+	}
+
+	if next == nil {
+		return false
+	}
+
+	source := formatStatement(next, 0)
+	return source != "" && strings.ContainsRune("([-", rune(source[0]))
+}
+
+// ブロックを"{"と"}"で囲んで書き出す。空のブロックは"{}"とする。
+func (f *formatter) block(b *BlockStatement) {
+	if len(b.Statements) == 0 {
+		f.out.WriteString("{}")
+		return
+	}
+
+	f.out.WriteString("{\n")
+	f.depth++
+	f.statements(b.Statements)
+	f.depth--
+	f.indent()
+	f.out.WriteString("}")
+}
+
+// 式を書き出す。
+func (f *formatter) expression(e Expression) {
+	switch e := e.(type) {
+	case *Identifier:
+		f.out.WriteString(e.Value)
+	case *IntegerLiteral, *FloatLiteral, *Boolean, *NullLiteral:
+		f.out.WriteString(e.TokenLiteral())
+	case *StringLiteral:
+		f.out.WriteString(`"` + stringLiteralEscaper.Replace(e.Value) + `"`)
+	case *TemplateLiteral:
+		f.out.WriteString("`")
+		for i, s := range e.Strings {
+			f.out.WriteString(escapeTemplateString(s))
+			if i < len(e.Expressions) {
+				f.out.WriteString("${")
+				f.expression(e.Expressions[i])
+				f.out.WriteString("}")
+			}
+		}
+		f.out.WriteString("`")
+	case *PrefixExpression:
+		f.out.WriteString(e.Operator)
+		f.operand(e.Right, formatPrefixPrecedence)
+	case *InfixExpression:
+		// 中置演算子は左結合のため、右辺は同じ優先順位でも括弧で囲む。
+		left, right := formatPostfixPrecedence, formatPostfixPrecedence
+		if precedence, ok := formatPrecedences[e.Operator]; ok {
+			left, right = precedence, precedence+1
+		}
+		f.operand(e.Left, left)
+		f.out.WriteString(" " + e.Operator + " ")
+		f.operand(e.Right, right)
+	case *IfExpression:
+		f.out.WriteString("if (")
+		f.expression(e.Condition)
+		f.out.WriteString(") ")
+		f.block(e.Consequence)
+		if elseIf, ok := e.ElseIf(); ok {
+			f.out.WriteString(" else ")
+			f.expression(elseIf)
+		} else if e.Alternative != nil {
+			f.out.WriteString(" else ")
+			f.block(e.Alternative)
+		}
+	case *SwitchExpression:
+		f.out.WriteString("switch (")
+		f.expression(e.Subject)
+		f.out.WriteString(") {\n")
+		for _, c := range e.Cases {
+			f.indent()
+			f.out.WriteString("case ")
+			f.expressions(c.Values)
+			f.out.WriteString(":\n")
+			f.caseBody(c.Body)
+		}
+		if e.Default != nil {
+			f.indent()
+			f.out.WriteString("default:\n")
+			f.caseBody(e.Default)
+		}
+		f.indent()
+		f.out.WriteString("}")
+	case *TryExpression:
+		f.out.WriteString("try ")
+		f.block(e.Body)
+		f.out.WriteString(" catch (" + e.CatchParam.Value + ") ")
+		f.block(e.Handler)
+	case *HashLiteral:
+		f.out.WriteString("{")
+		for i, key := range e.OrderedKeys() {
+			if i > 0 {
+				f.out.WriteString(", ")
+			}
+			f.expression(key)
+			if value := e.Pairs[key]; value != nil {
+				f.out.WriteString(": ")
+				f.expression(value)
+			}
+		}
+		f.out.WriteString("}")
+	case *IndexExpression:
+		f.operand(e.Left, formatPostfixPrecedence)
+		f.out.WriteString("[")
+		f.expression(e.Index)
+		f.out.WriteString("]")
+	case *SliceExpression:
+		f.operand(e.Left, formatPostfixPrecedence)
+		f.out.WriteString("[")
+		if e.Start != nil {
+			f.expression(e.Start)
+		}
+		f.out.WriteString(":")
+		if e.End != nil {
+			f.expression(e.End)
+		}
+		f.out.WriteString("]")
+	case *ArrayLiteral:
+		f.out.WriteString("[")
+		f.expressions(e.Elements)
+		f.out.WriteString("]")
+	case *TupleLiteral:
+		f.expressions(e.Elements)
+	case *SpreadExpression:
+		f.out.WriteString("...")
+		f.expression(e.Value)
+	case *FunctionLiteral:
+		f.out.WriteString("fn")
+		if e.Name != nil {
+			f.out.WriteString(" " + e.Name.Value)
+		}
+		f.out.WriteString("(")
+		for i, p := range e.Parameters {
+			if i > 0 {
+				f.out.WriteString(", ")
+			}
+			f.out.WriteString(p.Value)
+			if value, ok := e.Defaults[p.Value]; ok {
+				f.out.WriteString(" = ")
+				f.expression(value)
+			}
+		}
+		if e.Rest != nil {
+			if len(e.Parameters) > 0 {
+				f.out.WriteString(", ")
+			}
+			f.out.WriteString("..." + e.Rest.Value)
+		}
+		f.out.WriteString(") ")
+		f.block(e.Body)
+	case *CallExpression:
+		f.operand(e.Function, formatPostfixPrecedence)
+		f.out.WriteString("(")
+		f.expressions(e.Arguments)
+		f.out.WriteString(")")
+	case *MacroLiteral:
+		f.out.WriteString("macro(")
+		for i, p := range e.Parameters {
+			if i > 0 {
+				f.out.WriteString(", ")
+			}
+			f.out.WriteString(p.Value)
+		}
+		f.out.WriteString(") ")
+		f.block(e.Body)
+	}
+}
+
+// 式をカンマで区切って書き出す。
+func (f *formatter) expressions(exps []Expression) {
+	for i, e := range exps {
+		if i > 0 {
+			f.out.WriteString(", ")
+		}
+		f.expression(e)
+	}
+}
+
+// 演算子の被演算子を書き出す。被演算子の優先順位がminPrecedenceより低ければ括弧で囲む。
+func (f *formatter) operand(e Expression, minPrecedence int) {
+	if expressionPrecedence(e) < minPrecedence {
+		f.out.WriteString("(")
+		f.expression(e)
+		f.out.WriteString(")")
+		return
+	}
+	f.expression(e)
+}
+
+// 式の優先順位を返す。
+func expressionPrecedence(e Expression) int {
+	switch e := e.(type) {
+	case *InfixExpression:
+		if precedence, ok := formatPrecedences[e.Operator]; ok {
+			return precedence
+		}
+		return formatUnknownPrecedence
+	case *PrefixExpression:
+		return formatPrefixPrecedence
+	case *CallExpression, *IndexExpression, *SliceExpression:
+		return formatPostfixPrecedence
+	default:
+		return formatPrimaryPrecedence
+	}
+}
+
+// caseとdefaultの本体を、1段深いインデントで書き出す。
+func (f *formatter) caseBody(b *BlockStatement) {
+	f.depth++
+	f.statements(b.Statements)
+	f.depth--
+}
+
+// 文字列リテラルの中でエスケープが必要な文字の置き換え
+var stringLiteralEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// テンプレート文字列の地の文をエスケープする。
+// '`'と、埋め込み式の始まりと解釈される"${"の'$'もエスケープする。
+func escapeTemplateString(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "`", "\\`", "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(s)
+	return strings.ReplaceAll(s, "${", `\${`)
+}
@@ -0,0 +1,306 @@
+package ast
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// 各ノードをJSONにシリアライズする。
+// どのノードもnodeTypeフィールドでノードの種別を区別できる。
+
+func (p *Program) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType   string      `json:"nodeType"`
+		Statements []Statement `json:"statements"`
+	}{"Program", p.Statements})
+}
+
+func (ls *LetStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string      `json:"nodeType"`
+		Name     *Identifier `json:"name"`
+		Value    Expression  `json:"value"`
+	}{"LetStatement", ls.Name, ls.Value})
+}
+
+func (cs *ConstStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string      `json:"nodeType"`
+		Name     *Identifier `json:"name"`
+		Value    Expression  `json:"value"`
+	}{"ConstStatement", cs.Name, cs.Value})
+}
+
+func (ds *DestructuringLetStatement) MarshalJSON() ([]byte, error) {
+	pattern := "array"
+	if ds.IsHash() {
+		pattern = "hash"
+	}
+
+	return json.Marshal(struct {
+		NodeType string        `json:"nodeType"`
+		Pattern  string        `json:"pattern"`
+		Names    []*Identifier `json:"names"`
+		Value    Expression    `json:"value"`
+	}{"DestructuringLetStatement", pattern, ds.Names, ds.Value})
+}
+
+func (as *AssignStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string      `json:"nodeType"`
+		Name     *Identifier `json:"name"`
+		Value    Expression  `json:"value"`
+	}{"AssignStatement", as.Name, as.Value})
+}
+
+func (i *Identifier) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string `json:"nodeType"`
+		Value    string `json:"value"`
+	}{"Identifier", i.Value})
+}
+
+func (rs *ReturnStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType    string     `json:"nodeType"`
+		ReturnValue Expression `json:"returnValue"`
+	}{"ReturnStatement", rs.ReturnValue})
+}
+
+func (es *ExpressionStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType   string     `json:"nodeType"`
+		Expression Expression `json:"expression"`
+	}{"ExpressionStatement", es.Expression})
+}
+
+func (bs *BlockStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType   string      `json:"nodeType"`
+		Statements []Statement `json:"statements"`
+	}{"BlockStatement", bs.Statements})
+}
+
+func (ws *WhileStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType  string          `json:"nodeType"`
+		Condition Expression      `json:"condition"`
+		Body      *BlockStatement `json:"body"`
+	}{"WhileStatement", ws.Condition, ws.Body})
+}
+
+func (fs *ForStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType  string          `json:"nodeType"`
+		Init      Statement       `json:"init"`
+		Condition Expression      `json:"condition"`
+		Post      Statement       `json:"post"`
+		Body      *BlockStatement `json:"body"`
+	}{"ForStatement", fs.Init, fs.Condition, fs.Post, fs.Body})
+}
+
+func (bs *BreakStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string `json:"nodeType"`
+	}{"BreakStatement"})
+}
+
+func (cs *ContinueStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string `json:"nodeType"`
+	}{"ContinueStatement"})
+}
+
+func (il *IntegerLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string `json:"nodeType"`
+		Value    int64  `json:"value"`
+	}{"IntegerLiteral", il.Value})
+}
+
+func (fl *FloatLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string  `json:"nodeType"`
+		Value    float64 `json:"value"`
+	}{"FloatLiteral", fl.Value})
+}
+
+func (sl *StringLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string `json:"nodeType"`
+		Value    string `json:"value"`
+	}{"StringLiteral", sl.Value})
+}
+
+func (tl *TemplateLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType    string       `json:"nodeType"`
+		Strings     []string     `json:"strings"`
+		Expressions []Expression `json:"expressions"`
+	}{"TemplateLiteral", tl.Strings, tl.Expressions})
+}
+
+func (b *Boolean) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string `json:"nodeType"`
+		Value    bool   `json:"value"`
+	}{"Boolean", b.Value})
+}
+
+func (nl *NullLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string `json:"nodeType"`
+	}{"NullLiteral"})
+}
+
+func (pe *PrefixExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string     `json:"nodeType"`
+		Operator string     `json:"operator"`
+		Right    Expression `json:"right"`
+	}{"PrefixExpression", pe.Operator, pe.Right})
+}
+
+func (ie *InfixExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string     `json:"nodeType"`
+		Operator string     `json:"operator"`
+		Left     Expression `json:"left"`
+		Right    Expression `json:"right"`
+	}{"InfixExpression", ie.Operator, ie.Left, ie.Right})
+}
+
+func (ie *IfExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType    string          `json:"nodeType"`
+		Condition   Expression      `json:"condition"`
+		Consequence *BlockStatement `json:"consequence"`
+		Alternative *BlockStatement `json:"alternative,omitempty"`
+	}{"IfExpression", ie.Condition, ie.Consequence, ie.Alternative})
+}
+
+// switchCaseJSON switch式のcase
+type switchCaseJSON struct {
+	Values []Expression    `json:"values"`
+	Body   *BlockStatement `json:"body"`
+}
+
+func (se *SwitchExpression) MarshalJSON() ([]byte, error) {
+	cases := []switchCaseJSON{}
+	for _, c := range se.Cases {
+		cases = append(cases, switchCaseJSON{Values: c.Values, Body: c.Body})
+	}
+
+	return json.Marshal(struct {
+		NodeType string           `json:"nodeType"`
+		Subject  Expression       `json:"subject"`
+		Cases    []switchCaseJSON `json:"cases"`
+		Default  *BlockStatement  `json:"default,omitempty"`
+	}{"SwitchExpression", se.Subject, cases, se.Default})
+}
+
+func (te *TryExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType   string          `json:"nodeType"`
+		Body       *BlockStatement `json:"body"`
+		CatchParam *Identifier     `json:"catchParam"`
+		Handler    *BlockStatement `json:"handler"`
+	}{"TryExpression", te.Body, te.CatchParam, te.Handler})
+}
+
+func (se *SpreadExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string     `json:"nodeType"`
+		Value    Expression `json:"value"`
+	}{"SpreadExpression", se.Value})
+}
+
+// hashPairJSON ハッシュリテラルのキーと値の組
+type hashPairJSON struct {
+	Key   Expression `json:"key"`
+	Value Expression `json:"value"`
+}
+
+// MarshalJSON 出力が決定的になるよう、キーと値の組はキーの文字列表現の順に並べる。
+func (hl *HashLiteral) MarshalJSON() ([]byte, error) {
+	pairs := []hashPairJSON{}
+	for key, value := range hl.Pairs {
+		pairs = append(pairs, hashPairJSON{Key: key, Value: value})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Key.String() < pairs[j].Key.String()
+	})
+
+	return json.Marshal(struct {
+		NodeType string         `json:"nodeType"`
+		Pairs    []hashPairJSON `json:"pairs"`
+	}{"HashLiteral", pairs})
+}
+
+func (ie *IndexExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string     `json:"nodeType"`
+		Left     Expression `json:"left"`
+		Index    Expression `json:"index"`
+	}{"IndexExpression", ie.Left, ie.Index})
+}
+
+func (se *SliceExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string     `json:"nodeType"`
+		Left     Expression `json:"left"`
+		Start    Expression `json:"start"`
+		End      Expression `json:"end"`
+	}{"SliceExpression", se.Left, se.Start, se.End})
+}
+
+func (al *ArrayLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string       `json:"nodeType"`
+		Elements []Expression `json:"elements"`
+	}{"ArrayLiteral", al.Elements})
+}
+
+func (tl *TupleLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType string       `json:"nodeType"`
+		Elements []Expression `json:"elements"`
+	}{"TupleLiteral", tl.Elements})
+}
+
+// parameterJSON 関数リテラルの仮引数とデフォルト値の組
+type parameterJSON struct {
+	Name    *Identifier `json:"name"`
+	Default Expression  `json:"default,omitempty"`
+}
+
+func (fl *FunctionLiteral) MarshalJSON() ([]byte, error) {
+	params := []parameterJSON{}
+	for _, p := range fl.Parameters {
+		params = append(params, parameterJSON{Name: p, Default: fl.Defaults[p.Value]})
+	}
+
+	return json.Marshal(struct {
+		NodeType   string          `json:"nodeType"`
+		Name       *Identifier     `json:"name,omitempty"`
+		Parameters []parameterJSON `json:"parameters"`
+		Rest       *Identifier     `json:"rest,omitempty"`
+		Body       *BlockStatement `json:"body"`
+	}{"FunctionLiteral", fl.Name, params, fl.Rest, fl.Body})
+}
+
+func (ce *CallExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType  string       `json:"nodeType"`
+		Function  Expression   `json:"function"`
+		Arguments []Expression `json:"arguments"`
+	}{"CallExpression", ce.Function, ce.Arguments})
+}
+
+func (ml *MacroLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		NodeType   string          `json:"nodeType"`
+		Parameters []*Identifier   `json:"parameters"`
+		Body       *BlockStatement `json:"body"`
+	}{"MacroLiteral", ml.Parameters, ml.Body})
+}
@@ -0,0 +1,260 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Visitor Walkで訪問するノードごとにVisitが呼ばれる。
+// Visitが返したVisitorがnilでなければ、そのVisitorでノードの子を訪問し、
+// 最後にVisit(nil)を呼ぶ。
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk 深さ優先でASTを走査する。
+// v.Visit(node)を呼び、返されたVisitorがnilでなければ、nodeの子それぞれについて再帰的にWalkを呼ぶ。
+// 省略された子（nil）は訪問しない。
+func Walk(node Node, v Visitor) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(n.Statements, v)
+
+	case *LetStatement:
+		if n.Name != nil {
+			Walk(n.Name, v)
+		}
+		if n.Value != nil {
+			Walk(n.Value, v)
+		}
+
+	case *ConstStatement:
+		if n.Name != nil {
+			Walk(n.Name, v)
+		}
+		if n.Value != nil {
+			Walk(n.Value, v)
+		}
+
+	case *DestructuringLetStatement:
+		for _, name := range n.Names {
+			if name != nil {
+				Walk(name, v)
+			}
+		}
+		if n.Value != nil {
+			Walk(n.Value, v)
+		}
+
+	case *AssignStatement:
+		if n.Name != nil {
+			Walk(n.Name, v)
+		}
+		if n.Value != nil {
+			Walk(n.Value, v)
+		}
+
+	case *ReturnStatement:
+		if n.ReturnValue != nil {
+			Walk(n.ReturnValue, v)
+		}
+
+	case *ExpressionStatement:
+		if n.Expression != nil {
+			Walk(n.Expression, v)
+		}
+
+	case *BlockStatement:
+		walkStatements(n.Statements, v)
+
+	case *WhileStatement:
+		if n.Condition != nil {
+			Walk(n.Condition, v)
+		}
+		if n.Body != nil {
+			Walk(n.Body, v)
+		}
+
+	case *ForStatement:
+		if n.Init != nil {
+			Walk(n.Init, v)
+		}
+		if n.Condition != nil {
+			Walk(n.Condition, v)
+		}
+		if n.Post != nil {
+			Walk(n.Post, v)
+		}
+		if n.Body != nil {
+			Walk(n.Body, v)
+		}
+
+	case *BreakStatement, *ContinueStatement,
+		*Identifier, *IntegerLiteral, *FloatLiteral, *StringLiteral, *Boolean, *NullLiteral:
+		// 子を持たない。
+
+	case *TemplateLiteral:
+		walkExpressions(n.Expressions, v)
+
+	case *PrefixExpression:
+		if n.Right != nil {
+			Walk(n.Right, v)
+		}
+
+	case *InfixExpression:
+		if n.Left != nil {
+			Walk(n.Left, v)
+		}
+		if n.Right != nil {
+			Walk(n.Right, v)
+		}
+
+	case *IfExpression:
+		if n.Condition != nil {
+			Walk(n.Condition, v)
+		}
+		if n.Consequence != nil {
+			Walk(n.Consequence, v)
+		}
+		if n.Alternative != nil {
+			Walk(n.Alternative, v)
+		}
+
+	case *SwitchExpression:
+		if n.Subject != nil {
+			Walk(n.Subject, v)
+		}
+		for _, c := range n.Cases {
+			if c == nil {
+				continue
+			}
+			walkExpressions(c.Values, v)
+			if c.Body != nil {
+				Walk(c.Body, v)
+			}
+		}
+		if n.Default != nil {
+			Walk(n.Default, v)
+		}
+
+	case *TryExpression:
+		if n.Body != nil {
+			Walk(n.Body, v)
+		}
+		if n.CatchParam != nil {
+			Walk(n.CatchParam, v)
+		}
+		if n.Handler != nil {
+			Walk(n.Handler, v)
+		}
+
+	case *HashLiteral:
+		// 走査順が決定的になるよう、キーの文字列表現の順に訪問する。
+		keys := make([]Expression, 0, len(n.Pairs))
+		for key := range n.Pairs {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		for _, key := range keys {
+			Walk(key, v)
+			if value := n.Pairs[key]; value != nil {
+				Walk(value, v)
+			}
+		}
+
+	case *IndexExpression:
+		if n.Left != nil {
+			Walk(n.Left, v)
+		}
+		if n.Index != nil {
+			Walk(n.Index, v)
+		}
+
+	case *SliceExpression:
+		if n.Left != nil {
+			Walk(n.Left, v)
+		}
+		if n.Start != nil {
+			Walk(n.Start, v)
+		}
+		if n.End != nil {
+			Walk(n.End, v)
+		}
+
+	case *ArrayLiteral:
+		walkExpressions(n.Elements, v)
+
+	case *TupleLiteral:
+		walkExpressions(n.Elements, v)
+
+	case *SpreadExpression:
+		if n.Value != nil {
+			Walk(n.Value, v)
+		}
+
+	case *FunctionLiteral:
+		if n.Name != nil {
+			Walk(n.Name, v)
+		}
+		for _, param := range n.Parameters {
+			if param == nil {
+				continue
+			}
+			Walk(param, v)
+			if value := n.Defaults[param.Value]; value != nil {
+				Walk(value, v)
+			}
+		}
+		if n.Rest != nil {
+			Walk(n.Rest, v)
+		}
+		if n.Body != nil {
+			Walk(n.Body, v)
+		}
+
+	case *CallExpression:
+		if n.Function != nil {
+			Walk(n.Function, v)
+		}
+		walkExpressions(n.Arguments, v)
+
+	case *MacroLiteral:
+		for _, param := range n.Parameters {
+			if param != nil {
+				Walk(param, v)
+			}
+		}
+		if n.Body != nil {
+			Walk(n.Body, v)
+		}
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+// 文のスライスを順に走査する。
+func walkStatements(statements []Statement, v Visitor) {
+	for _, s := range statements {
+		if s != nil {
+			Walk(s, v)
+		}
+	}
+}
+
+// 式のスライスを順に走査する。
+func walkExpressions(expressions []Expression, v Visitor) {
+	for _, e := range expressions {
+		if e != nil {
+			Walk(e, v)
+		}
+	}
+}
@@ -6,6 +6,8 @@ require local.packages/ast v0.0.0
 
 require local.packages/lexer v0.0.0
 
+require local.packages/object v0.0.0
+
 require local.packages/parser v0.0.0
 
 require local.packages/repl v0.0.0
@@ -16,6 +18,8 @@ replace local.packages/ast => ./ast
 
 replace local.packages/lexer => ./lexer
 
+replace local.packages/object => ./object
+
 replace local.packages/parser => ./parser
 
 replace local.packages/repl => ./repl
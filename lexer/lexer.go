@@ -1,45 +1,111 @@
 package lexer
 
-import "local.packages/token"
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+
+	"local.packages/token"
+)
 
 // Lexer 字句
+// 状態はすべてLexerごとに持つため、別々に生成したLexerは並行して使える。
 type Lexer struct {
-	// 入力値
-	input string
-	// 現在の文字の位置
-	position int
-	// これから読み込む位置（現在の文字の次）
-	readPosition int
+	// 入力元
+	// 入力全体をメモリに載せず、必要に応じてバッファリングしながら読み込む。
+	reader *bufio.Reader
 	// 現在検査中の文字
-	ch byte
+	ch rune
+	// 現在検査中の文字の次の文字（先読み）
+	peekCh rune
+	// 現在検査中の文字の行番号（1始まり）
+	line int
+	// 現在検査中の文字の列番号（1始まり）
+	column int
+	// 現在検査中の文字の、入力の先頭からのバイト数（0始まり）
+	offset int
+	// 現在検査中の文字と次の文字の、UTF-8でのバイト数
+	chSize   int
+	peekSize int
+	// 空白とコメントを読み飛ばさず、トークンとして返すかどうか
+	keepTrivia bool
+	// リテラルを読み取るための作業用バッファ
+	// トークンごとに確保し直さないよう使い回す。
+	buf bytes.Buffer
+	// 読み込み中のテンプレート文字列のスタック
+	// 要素は埋め込み式の中で閉じられていない'{'の数で、地の文を読んでいる間はinTemplateTextとする。
+	templates []int
 }
 
+// テンプレート文字列の地の文を読んでいることを表す。
+const inTemplateText = -1
+
 // New Lexerを生成して返す。
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewReader(strings.NewReader(input))
+}
+
+// NewReader io.Readerから入力を読み込むLexerを生成して返す。
+func NewReader(r io.Reader) *Lexer {
+	l := &Lexer{reader: bufio.NewReader(r), line: 1}
+	// 先読みの文字と現在の文字をセットする。
+	l.peekCh, l.peekSize = l.readRune()
 	l.readChar()
 	return l
 }
 
+// 入力元から1文字読み込んで、その文字とUTF-8でのバイト数を返す。
+// 末端に到達した場合や読み込みに失敗した場合は0を返す。
+// ASCIIコードの"NUL"文字に対応している。
+func (l *Lexer) readRune() (rune, int) {
+	r, size, err := l.reader.ReadRune()
+	if err != nil {
+		return 0, 0
+	}
+	return r, size
+}
+
 // 次の文字を読んで、入力値の現在位置を進める。
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
-		// 末端に到達した場合。
-		// ASCIIコードの"NUL"文字に対応している。
-		l.ch = 0
-	} else {
-		// それ以外の場合は、その位置にある文字を読み取る。
-		l.ch = l.input[l.readPosition]
+	if l.ch == '\n' {
+		// 改行を読み終えたら次の行へ進み、列をリセットする。
+		l.line += 1
+		l.column = 0
+	}
+	l.offset += l.chSize
+	l.ch, l.chSize = l.peekCh, l.peekSize
+	if l.ch != 0 {
+		// 末端に到達していなければ、さらに次の文字を先読みする。
+		l.peekCh, l.peekSize = l.readRune()
 	}
-	l.position = l.readPosition
-	l.readPosition += 1
+	l.column += 1
+}
+
+// Position 次に読む文字の行番号と列番号を返す。
+// NextTokenの直後に呼び出すと、返したトークンの最後の文字の次の位置になる。
+func (l *Lexer) Position() (line, column int) {
+	return l.line, l.column
 }
 
 // NextToken 次の文字からtoken.Tokenを生成して返す。
 func (l *Lexer) NextToken() token.Token {
 	var t token.Token
 
-	l.skipWhitespace()
+	if n := len(l.templates); n > 0 && l.templates[n-1] == inTemplateText {
+		// テンプレート文字列の地の文では空白もそのまま読む。
+		return l.nextTemplateToken()
+	}
+
+	if !l.keepTrivia {
+		l.skipWhitespace()
+	} else if t, ok := l.readTrivia(); ok {
+		return t
+	}
+
+	// トークンの開始位置を覚えておく。
+	line, column, offset := l.line, l.column, l.offset
 
 	switch l.ch {
 	case '=':
@@ -64,20 +130,131 @@ func (l *Lexer) NextToken() token.Token {
 			// "!" の場合
 			t = newToken(token.BANG, l.ch)
 		}
+	case '&':
+		if l.peekChar() == '&' {
+			// "&&"の場合
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			t = token.Token{Type: token.AND, Literal: literal}
+		} else {
+			// "&" の場合
+			t = newToken(token.AMPERSAND, l.ch)
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			// "||"の場合
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			t = token.Token{Type: token.OR, Literal: literal}
+		} else {
+			// "|" の場合
+			t = newToken(token.PIPE, l.ch)
+		}
+	case '^':
+		t = newToken(token.CARET, l.ch)
+	case '~':
+		t = newToken(token.TILDE, l.ch)
 	case '+':
-		t = newToken(token.PLUS, l.ch)
+		if l.peekChar() == '=' {
+			// "+="の場合
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			t = token.Token{Type: token.PLUS_EQ, Literal: literal}
+		} else {
+			// "+" の場合
+			t = newToken(token.PLUS, l.ch)
+		}
 	case '-':
-		t = newToken(token.MINUS, l.ch)
+		if l.peekChar() == '=' {
+			// "-="の場合
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			t = token.Token{Type: token.MINUS_EQ, Literal: literal}
+		} else {
+			// "-" の場合
+			t = newToken(token.MINUS, l.ch)
+		}
 	case '/':
-		t = newToken(token.SLASH, l.ch)
+		if l.peekChar() == '/' {
+			// "//"の場合は行コメントとして読み飛ばし、続くトークンを返す。
+			l.skipComment()
+			return l.NextToken()
+		} else if l.peekChar() == '*' {
+			// "/*"の場合はブロックコメントとして読み飛ばし、続くトークンを返す。
+			if l.skipBlockComment() {
+				return l.NextToken()
+			}
+			// 閉じられないまま入力の末尾に達した場合
+			t = token.Token{Type: token.ILLEGAL, Literal: "/*"}
+		} else if l.peekChar() == '=' {
+			// "/="の場合
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			t = token.Token{Type: token.SLASH_EQ, Literal: literal}
+		} else {
+			// "/" の場合
+			t = newToken(token.SLASH, l.ch)
+		}
 	case '*':
-		t = newToken(token.ASTERISK, l.ch)
+		if l.peekChar() == '=' {
+			// "*="の場合
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			t = token.Token{Type: token.ASTERISK_EQ, Literal: literal}
+		} else {
+			// "*" の場合
+			t = newToken(token.ASTERISK, l.ch)
+		}
+	case '%':
+		t = newToken(token.PERCENT, l.ch)
 	case '<':
-		t = newToken(token.LT, l.ch)
+		if l.peekChar() == '<' {
+			// "<<"の場合
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			t = token.Token{Type: token.LSHIFT, Literal: literal}
+		} else {
+			// "<" の場合
+			t = newToken(token.LT, l.ch)
+		}
 	case '>':
-		t = newToken(token.GT, l.ch)
+		if l.peekChar() == '>' {
+			// ">>"の場合
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			t = token.Token{Type: token.RSHIFT, Literal: literal}
+		} else {
+			// ">" の場合
+			t = newToken(token.GT, l.ch)
+		}
 	case ';':
 		t = newToken(token.SEMICOLON, l.ch)
+	case ':':
+		t = newToken(token.COLON, l.ch)
+	case '.':
+		if l.peekChar() == '.' {
+			ch := l.ch
+			l.readChar()
+			if l.peekChar() == '.' {
+				// "..."の場合
+				l.readChar()
+				t = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+			} else {
+				// ".." は未知のトークン
+				t = token.Token{Type: token.ILLEGAL, Literal: string(ch) + string(l.ch)}
+			}
+		} else {
+			// "." 単体は未知のトークン
+			t = newToken(token.ILLEGAL, l.ch)
+		}
 	case '(':
 		t = newToken(token.LPAREN, l.ch)
 	case ')':
@@ -85,9 +262,35 @@ func (l *Lexer) NextToken() token.Token {
 	case ',':
 		t = newToken(token.COMMA, l.ch)
 	case '{':
+		if n := len(l.templates); n > 0 {
+			l.templates[n-1]++
+		}
 		t = newToken(token.LBRACE, l.ch)
 	case '}':
+		if n := len(l.templates); n > 0 {
+			if l.templates[n-1] == 0 {
+				// 埋め込み式の終わりなので、テンプレート文字列の地の文に戻る。
+				l.templates[n-1] = inTemplateText
+			} else {
+				l.templates[n-1]--
+			}
+		}
 		t = newToken(token.RBRACE, l.ch)
+	case '[':
+		t = newToken(token.LBRACKET, l.ch)
+	case ']':
+		t = newToken(token.RBRACKET, l.ch)
+	case '"':
+		literal, ok := l.readString()
+		if ok {
+			t = token.Token{Type: token.STRING, Literal: literal}
+		} else {
+			// 閉じられないまま入力の末尾に達した場合は、開き引用符から末尾までをILLEGALとする。
+			t = token.Token{Type: token.ILLEGAL, Literal: `"` + literal}
+		}
+	case '`':
+		l.templates = append(l.templates, inTemplateText)
+		t = newToken(token.BACKTICK, l.ch)
 	case 0:
 		t.Literal = ""
 		t.Type = token.EOF
@@ -96,11 +299,12 @@ func (l *Lexer) NextToken() token.Token {
 			// 識別子の場合
 			t.Literal = l.readIdentifier()
 			t.Type = token.LookupIdentifier(t.Literal)
+			t.Line, t.Column, t.Offset = line, column, offset
 			return t
 		} else if isDigit(l.ch) {
-			// 整数リテラルの場合
-			t.Type = token.INT
-			t.Literal = l.readNumber()
+			// 数値リテラルの場合
+			t.Type, t.Literal = l.readNumber()
+			t.Line, t.Column, t.Offset = line, column, offset
 			return t
 		} else {
 			// 不明なトークンの場合
@@ -109,56 +313,326 @@ func (l *Lexer) NextToken() token.Token {
 	}
 
 	l.readChar()
+	t.Line, t.Column, t.Offset = line, column, offset
 	return t
 }
 
 // token.Tokenを生成して返す。
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
 // 現在位置の次の位置の文字を返す。
-// positionは進めない。
+// 現在位置は進めない。
 // また、現在位置が末尾の時は0を返す。
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
-		return 0
-	} else {
-		return l.input[l.readPosition]
-	}
+func (l *Lexer) peekChar() rune {
+	return l.peekCh
 }
 
 // 連続する文字を識別子として取り出して文字列として返す。
 func (l *Lexer) readIdentifier() string {
-	position := l.position
+	l.buf.Reset()
 	for isLetter(l.ch) {
+		l.buf.WriteRune(l.ch)
+		l.readChar()
+	}
+	return l.buf.String()
+}
+
+// Unicodeの文字（漢字やかなを含む）または_にマッチする場合にtrueを返す。
+// 絵文字などの記号は文字として扱わない。
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
+}
+
+// 引用符で囲まれた文字列を取り出して、エスケープシーケンスを解釈した文字列を返す。
+// 未知のエスケープシーケンスはバックスラッシュを含めてそのまま残す。
+// 閉じ引用符が無いまま入力の末尾に達した場合は、それまでに読んだ文字列とfalseを返す。
+func (l *Lexer) readString() (string, bool) {
+	l.buf.Reset()
+
+	for {
+		l.readChar()
+		if l.ch == '"' {
+			return l.buf.String(), true
+		}
+		if l.ch == 0 {
+			return l.buf.String(), false
+		}
+
+		if l.ch != '\\' {
+			l.buf.WriteRune(l.ch)
+			continue
+		}
+
+		l.readChar()
+		if !l.writeEscape() {
+			return l.buf.String(), false
+		}
+	}
+}
+
+// バックスラッシュに続く現在の文字をエスケープシーケンスとして解釈し、作業用バッファに書き込む。
+// バックスラッシュの直後で入力の末尾に達した場合は、バックスラッシュだけを書き込んでfalseを返す。
+func (l *Lexer) writeEscape() bool {
+	switch l.ch {
+	case 'n':
+		l.buf.WriteByte('\n')
+	case 't':
+		l.buf.WriteByte('\t')
+	case 'r':
+		l.buf.WriteByte('\r')
+	case '"':
+		l.buf.WriteByte('"')
+	case '\\':
+		l.buf.WriteByte('\\')
+	case 0:
+		l.buf.WriteByte('\\')
+		return false
+	default:
+		l.buf.WriteByte('\\')
+		l.buf.WriteRune(l.ch)
+	}
+	return true
+}
+
+// テンプレート文字列の地の文から次のトークンを生成して返す。
+// 閉じる'`'、埋め込み式の始まりの"${"、それ以外の文字の並びのいずれかを返す。
+func (l *Lexer) nextTemplateToken() token.Token {
+	var t token.Token
+
+	line, column, offset := l.line, l.column, l.offset
+
+	switch {
+	case l.ch == '`':
+		l.templates = l.templates[:len(l.templates)-1]
+		t = newToken(token.BACKTICK, l.ch)
+		l.readChar()
+	case l.ch == '$' && l.peekChar() == '{':
+		l.templates[len(l.templates)-1] = 0
+		t = token.Token{Type: token.DOLLAR_LBRACE, Literal: "${"}
+		l.readChar()
 		l.readChar()
+	case l.ch == 0:
+		t = token.Token{Type: token.EOF, Literal: ""}
+	default:
+		t = token.Token{Type: token.TEMPLATE_STRING, Literal: l.readTemplateString()}
 	}
-	return l.input[position:l.position]
+
+	t.Line, t.Column, t.Offset = line, column, offset
+	return t
 }
 
-// a-zA-z_にマッチする場合にtrueを返す。
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// テンプレート文字列の地の文を、'`'か"${"の手前まで取り出して、エスケープシーケンスを解釈した文字列を返す。
+// 文字列リテラルのエスケープシーケンスに加えて、"\`"と"\$"を使える。"\${"は埋め込み式にならない。
+func (l *Lexer) readTemplateString() string {
+	l.buf.Reset()
+
+	for l.ch != '`' && l.ch != 0 && !(l.ch == '$' && l.peekChar() == '{') {
+		if l.ch != '\\' {
+			l.buf.WriteRune(l.ch)
+			l.readChar()
+			continue
+		}
+
+		l.readChar()
+		switch l.ch {
+		case '`', '$':
+			l.buf.WriteRune(l.ch)
+		default:
+			if !l.writeEscape() {
+				return l.buf.String()
+			}
+		}
+		l.readChar()
+	}
+
+	return l.buf.String()
+}
+
+// 連続する数字を取り出して、数値の種別と文字列を返す。
+// "0x"で始まる場合は16進数、"0b"で始まる場合は2進数として読み取る。
+// 小数点の後に数字が続く場合は浮動小数点数として読み取る。
+// 桁区切りのアンダースコアが不正な場合はILLEGALを返す。
+// "12."のように小数点の後に数字が続かない場合、小数点は数値に含めない。
+func (l *Lexer) readNumber() (token.TokenType, string) {
+	l.buf.Reset()
+
+	if l.ch == '0' && isRadixPrefix(l.peekChar()) {
+		// "0x"で始まる16進数、"0b"で始まる2進数の場合
+		// 不正な文字も含めて読み取り、値の検証は構文解析に任せる。桁区切りのアンダースコアの位置だけはここで検証する。
+		for i := 0; i < 2; i++ {
+			l.buf.WriteRune(l.ch)
+			l.readChar()
+		}
+		for isLetter(l.ch) || isDigit(l.ch) {
+			l.buf.WriteRune(l.ch)
+			l.readChar()
+		}
+
+		literal := l.buf.String()
+		if !isValidRadixSeparator(literal[2:]) {
+			return token.ILLEGAL, literal
+		}
+		return token.INT, literal
+	}
+
+	var tokenType token.TokenType = token.INT
+	l.readDigits()
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		// 小数点を読み取って、続けて小数部を読み取る。
+		l.buf.WriteRune(l.ch)
+		l.readChar()
+		l.readDigits()
+		tokenType = token.FLOAT
+	}
+
+	literal := l.buf.String()
+	if !isValidDigitSeparator(literal) {
+		return token.ILLEGAL, literal
+	}
+	return tokenType, literal
 }
 
-// 連続する数字を取り出して文字列として返す。
-func (l *Lexer) readNumber() string {
-	position := l.position
-	for isDigit(l.ch) {
+// 連続する数字を読み取って作業用バッファに書き込む。桁区切りのアンダースコアも数字の一部として読み取る。
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
+		l.buf.WriteRune(l.ch)
 		l.readChar()
 	}
-	return l.input[position:l.position]
+}
+
+// 数値リテラル中のアンダースコアがすべて数字に挟まれている場合にtrueを返す。
+// 末尾や連続するアンダースコア、小数点に隣接するアンダースコアは不正とする。
+func isValidDigitSeparator(literal string) bool {
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '_' {
+			continue
+		}
+		if i == 0 || i == len(literal)-1 || !isDigit(rune(literal[i-1])) || !isDigit(rune(literal[i+1])) {
+			return false
+		}
+	}
+	return true
+}
+
+// 基数付きの数値リテラルのプレフィックスより後の部分で、アンダースコアがすべて英数字に挟まれている場合にtrueを返す。
+// 先頭や末尾、連続するアンダースコアは不正とする。
+func isValidRadixSeparator(digits string) bool {
+	for i := 0; i < len(digits); i++ {
+		if digits[i] != '_' {
+			continue
+		}
+		if i == 0 || i == len(digits)-1 || digits[i-1] == '_' || digits[i+1] == '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// 基数を表すプレフィックス（"0x"、"0b"の2文字目）にマッチする場合にtrueを返す。
+func isRadixPrefix(ch rune) bool {
+	return ch == 'x' || ch == 'X' || ch == 'b' || ch == 'B'
 }
 
 // 0-9にマッチする場合にtrueを返す。
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
+// 空白文字の場合にtrueを返す。
+func isWhitespace(ch rune) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
 // 空白文字を読み飛ばす。
+// 以下の読み飛ばす関数は、読み飛ばした文字を作業用バッファに書き足す。
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
-		l.readChar()
+	for isWhitespace(l.ch) {
+		l.skipChar()
 	}
 }
+
+// 行末までを行コメントとして読み飛ばす。
+// 改行文字そのものは読み飛ばさない。
+func (l *Lexer) skipComment() {
+	for l.ch != '\n' && l.ch != 0 {
+		l.skipChar()
+	}
+}
+
+// "/*"から"*/"までをブロックコメントとして読み飛ばす。
+// ネストしたブロックコメントには対応しない。
+// 閉じられないまま入力の末尾に達した場合はfalseを返す。
+func (l *Lexer) skipBlockComment() bool {
+	// "/*"を読み飛ばす。
+	l.skipChar()
+	l.skipChar()
+
+	for l.ch != 0 {
+		if l.ch == '*' && l.peekChar() == '/' {
+			// "*/"を読み飛ばす。
+			l.skipChar()
+			l.skipChar()
+			return true
+		}
+		l.skipChar()
+	}
+
+	return false
+}
+
+// 現在の文字を作業用バッファに書き足して、次の文字へ進む。
+func (l *Lexer) skipChar() {
+	l.buf.WriteRune(l.ch)
+	l.readChar()
+}
+
+// 空白とコメントを1つのトークンとして読み取って返す。
+// 現在の文字が空白とコメントのどちらの始まりでもなければfalseを返す。
+// 閉じられていないブロックコメントは、入力の末尾までをILLEGALとする。
+func (l *Lexer) readTrivia() (token.Token, bool) {
+	t := token.Token{Line: l.line, Column: l.column, Offset: l.offset}
+	l.buf.Reset()
+
+	switch {
+	case isWhitespace(l.ch):
+		t.Type = token.WHITESPACE
+		l.skipWhitespace()
+	case l.ch == '/' && l.peekChar() == '/':
+		t.Type = token.COMMENT
+		l.skipComment()
+	case l.ch == '/' && l.peekChar() == '*':
+		t.Type = token.COMMENT
+		if !l.skipBlockComment() {
+			t.Type = token.ILLEGAL
+		}
+	default:
+		return t, false
+	}
+
+	t.Literal = l.buf.String()
+	return t, true
+}
+
+// Tokens 入力の末尾までのトークンを、NextTokenを繰り返し呼び出して返す。
+// AllTokensと揃えて、最後のトークンはEOFになる。
+func (l *Lexer) Tokens() []token.Token {
+	tokens := []token.Token{}
+	for {
+		t := l.NextToken()
+		tokens = append(tokens, t)
+		if t.Type == token.EOF {
+			return tokens
+		}
+	}
+}
+
+// AllTokens 入力の末尾までのトークンを、空白とコメントも含めて返す。最後のトークンはEOFになる。
+// トークンのOffsetから次のトークンのOffsetまでが、そのトークンの元のソース文字列になる。
+// 呼び出した後のLexerは、NextTokenでも空白とコメントを返す。
+func (l *Lexer) AllTokens() []token.Token {
+	l.keepTrivia = true
+	return l.Tokens()
+}
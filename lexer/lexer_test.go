@@ -1,7 +1,11 @@
 package lexer
 
 import (
+	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"local.packages/token"
 )
@@ -16,7 +20,7 @@ func TestNextToken(t *testing.T) {
 	};
 
 	let result = add(five, ten);
-	!-/*5;
+	!-/ *5;
 	5 < 10 > 5;
 
 	if (5 < 10) {
@@ -27,6 +31,19 @@ func TestNextToken(t *testing.T) {
 
 	10 == 10;
 	10 != 9;
+	10 % 3;
+	a && b || c;
+	{"foo": "bar"}
+	arr[0];
+	while (x) { x; }
+	for (;;) { break; continue; }
+	a += 1; a -= 1; a *= 2; a /= 2;
+	let n = null;
+	switch (x) { case 1: x; default: y; }
+	5 & 3 | 1 ^ 2 << 4 >> 1 && a || b < c > d;
+	~0 != !x;
+	try { x; } catch (e) { e; }
+	const pi = 3;
 	`
 
 	tests := []struct {
@@ -106,6 +123,125 @@ func TestNextToken(t *testing.T) {
 		{token.NOT_EQ, "!="},
 		{token.INT, "9"},
 		{token.SEMICOLON, ";"},
+		{token.INT, "10"},
+		{token.PERCENT, "%"},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.AND, "&&"},
+		{token.IDENT, "b"},
+		{token.OR, "||"},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+		{token.LBRACE, "{"},
+		{token.STRING, "foo"},
+		{token.COLON, ":"},
+		{token.STRING, "bar"},
+		{token.RBRACE, "}"},
+		{token.IDENT, "arr"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, ";"},
+		{token.WHILE, "while"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.FOR, "for"},
+		{token.LPAREN, "("},
+		{token.SEMICOLON, ";"},
+		{token.SEMICOLON, ";"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.BREAK, "break"},
+		{token.SEMICOLON, ";"},
+		{token.CONTINUE, "continue"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.IDENT, "a"},
+		{token.PLUS_EQ, "+="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.MINUS_EQ, "-="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.ASTERISK_EQ, "*="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.SLASH_EQ, "/="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "n"},
+		{token.ASSIGN, "="},
+		{token.NULL, "null"},
+		{token.SEMICOLON, ";"},
+		{token.SWITCH, "switch"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.CASE, "case"},
+		{token.INT, "1"},
+		{token.COLON, ":"},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+		{token.DEFAULT, "default"},
+		{token.COLON, ":"},
+		{token.IDENT, "y"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.INT, "5"},
+		{token.AMPERSAND, "&"},
+		{token.INT, "3"},
+		{token.PIPE, "|"},
+		{token.INT, "1"},
+		{token.CARET, "^"},
+		{token.INT, "2"},
+		{token.LSHIFT, "<<"},
+		{token.INT, "4"},
+		{token.RSHIFT, ">>"},
+		{token.INT, "1"},
+		{token.AND, "&&"},
+		{token.IDENT, "a"},
+		{token.OR, "||"},
+		{token.IDENT, "b"},
+		{token.LT, "<"},
+		{token.IDENT, "c"},
+		{token.GT, ">"},
+		{token.IDENT, "d"},
+		{token.SEMICOLON, ";"},
+		{token.TILDE, "~"},
+		{token.INT, "0"},
+		{token.NOT_EQ, "!="},
+		{token.BANG, "!"},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+		{token.TRY, "try"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.CATCH, "catch"},
+		{token.LPAREN, "("},
+		{token.IDENT, "e"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "e"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.CONST, "const"},
+		{token.IDENT, "pi"},
+		{token.ASSIGN, "="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
 		{token.EOF, ""},
 	}
 
@@ -123,3 +259,648 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestNextTokenPosition(t *testing.T) {
+	input := "let x = 5;\nlet y = 10;"
+
+	tests := []struct {
+		expectedType   token.TokenType
+		expectedLine   int
+		expectedColumn int
+		expectedOffset int
+	}{
+		{token.LET, 1, 1, 0},
+		{token.IDENT, 1, 5, 4},
+		{token.ASSIGN, 1, 7, 6},
+		{token.INT, 1, 9, 8},
+		{token.SEMICOLON, 1, 10, 9},
+		{token.LET, 2, 1, 11},
+		{token.IDENT, 2, 5, 15},
+		{token.ASSIGN, 2, 7, 17},
+		{token.INT, 2, 9, 19},
+		{token.SEMICOLON, 2, 11, 21},
+		{token.EOF, 2, 12, 22},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Line != tt.expectedLine {
+			t.Fatalf("tests[%d] - line wrong. expected=%d, got=%d", i, tt.expectedLine, tok.Line)
+		}
+
+		if tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - column wrong. expected=%d, got=%d", i, tt.expectedColumn, tok.Column)
+		}
+
+		if tok.Offset != tt.expectedOffset {
+			t.Fatalf("tests[%d] - offset wrong. expected=%d, got=%d", i, tt.expectedOffset, tok.Offset)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	input := "let x = 5; // five"
+
+	expected := []token.Token{
+		{Type: token.LET, Literal: "let", Line: 1, Column: 1, Offset: 0},
+		{Type: token.IDENT, Literal: "x", Line: 1, Column: 5, Offset: 4},
+		{Type: token.ASSIGN, Literal: "=", Line: 1, Column: 7, Offset: 6},
+		{Type: token.INT, Literal: "5", Line: 1, Column: 9, Offset: 8},
+		{Type: token.SEMICOLON, Literal: ";", Line: 1, Column: 10, Offset: 9},
+		{Type: token.EOF, Literal: "", Line: 1, Column: 19, Offset: 18},
+	}
+
+	tokens := New(input).Tokens()
+
+	if len(tokens) != len(expected) {
+		t.Fatalf("wrong number of tokens. expected=%d, got=%d", len(expected), len(tokens))
+	}
+
+	for i, tok := range expected {
+		if tokens[i] != tok {
+			t.Errorf("tokens[%d] wrong. expected=%+v, got=%+v", i, tok, tokens[i])
+		}
+	}
+}
+
+func TestAllTokens(t *testing.T) {
+	input := "let x = 5; // five\n/* 名前 */ x"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedOffset  int
+	}{
+		{token.LET, "let", 0},
+		{token.WHITESPACE, " ", 3},
+		{token.IDENT, "x", 4},
+		{token.WHITESPACE, " ", 5},
+		{token.ASSIGN, "=", 6},
+		{token.WHITESPACE, " ", 7},
+		{token.INT, "5", 8},
+		{token.SEMICOLON, ";", 9},
+		{token.WHITESPACE, " ", 10},
+		{token.COMMENT, "// five", 11},
+		{token.WHITESPACE, "\n", 18},
+		{token.COMMENT, "/* 名前 */", 19},
+		{token.WHITESPACE, " ", 31},
+		{token.IDENT, "x", 32},
+		{token.EOF, "", 33},
+	}
+
+	tokens := New(input).AllTokens()
+
+	if len(tokens) != len(tests) {
+		t.Fatalf("wrong number of tokens. expected=%d, got=%d", len(tests), len(tokens))
+	}
+
+	for i, tt := range tests {
+		tok := tokens[i]
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Offset != tt.expectedOffset {
+			t.Fatalf("tests[%d] - offset wrong. expected=%d, got=%d", i, tt.expectedOffset, tok.Offset)
+		}
+	}
+}
+
+func TestAllTokensRoundTrip(t *testing.T) {
+	tests := []string{
+		"let x = 5;\nlet y = x + 10;",
+		"  // comment only\n",
+		"let s = \"a\\n\\\"b\";\t/* block\n comment */",
+		"let 名前 = `こんにちは ${ {\"a\": 1}[\"a\"] }！`;",
+		"fn(first, ...rest) { rest[0:2] } 0x1F 1_000 3.14",
+		"@ 😀 /* unterminated",
+		"",
+	}
+
+	for _, input := range tests {
+		tokens := New(input).AllTokens()
+
+		var out strings.Builder
+		for i, tok := range tokens[:len(tokens)-1] {
+			source := input[tok.Offset:tokens[i+1].Offset]
+			switch tok.Type {
+			case token.STRING, token.TEMPLATE_STRING:
+				// リテラルはエスケープシーケンスを解釈した値になる。
+			default:
+				if tok.Literal != source {
+					t.Errorf("input %q: tokens[%d] literal wrong. expected=%q, got=%q", input, i, source, tok.Literal)
+				}
+			}
+			out.WriteString(source)
+		}
+
+		if eof := tokens[len(tokens)-1]; eof.Type != token.EOF || eof.Offset != len(input) {
+			t.Errorf("input %q: last token wrong. got=%+v", input, eof)
+		}
+
+		if out.String() != input {
+			t.Errorf("round trip failed. expected=%q, got=%q", input, out.String())
+		}
+	}
+}
+
+func TestNextTokenLineComment(t *testing.T) {
+	input := `// これはコメント
+	let x = 5; // five
+	10 / 2;
+	//
+	`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "10"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenEllipsis(t *testing.T) {
+	input := `fn(first, ...rest) .. ....`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.IDENT, "first"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RPAREN, ")"},
+		{token.ILLEGAL, ".."},
+		{token.ELLIPSIS, "..."},
+		{token.ILLEGAL, "."},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenTemplate(t *testing.T) {
+	input := "`Hello ${name}!` `a${ {\"k\": `x${y}`}[\"k\"] }b` `\\${no} \\` $`"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.BACKTICK, "`"},
+		{token.TEMPLATE_STRING, "Hello "},
+		{token.DOLLAR_LBRACE, "${"},
+		{token.IDENT, "name"},
+		{token.RBRACE, "}"},
+		{token.TEMPLATE_STRING, "!"},
+		{token.BACKTICK, "`"},
+		{token.BACKTICK, "`"},
+		{token.TEMPLATE_STRING, "a"},
+		{token.DOLLAR_LBRACE, "${"},
+		{token.LBRACE, "{"},
+		{token.STRING, "k"},
+		{token.COLON, ":"},
+		{token.BACKTICK, "`"},
+		{token.TEMPLATE_STRING, "x"},
+		{token.DOLLAR_LBRACE, "${"},
+		{token.IDENT, "y"},
+		{token.RBRACE, "}"},
+		{token.BACKTICK, "`"},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.STRING, "k"},
+		{token.RBRACKET, "]"},
+		{token.RBRACE, "}"},
+		{token.TEMPLATE_STRING, "b"},
+		{token.BACKTICK, "`"},
+		{token.BACKTICK, "`"},
+		{token.TEMPLATE_STRING, "${no} ` $"},
+		{token.BACKTICK, "`"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenUnterminatedTemplate(t *testing.T) {
+	l := New("`abc")
+
+	expected := []token.TokenType{token.BACKTICK, token.TEMPLATE_STRING, token.EOF, token.EOF}
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt, tok.Type)
+		}
+	}
+}
+
+func TestNextTokenUnterminatedString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected token.Token
+	}{
+		{`"unterminated`, token.Token{Type: token.ILLEGAL, Literal: `"unterminated`, Line: 1, Column: 1, Offset: 0}},
+		{`x = "a\nb`, token.Token{Type: token.ILLEGAL, Literal: "\"a\nb", Line: 1, Column: 5, Offset: 4}},
+		{`"abc\`, token.Token{Type: token.ILLEGAL, Literal: `"abc\`, Line: 1, Column: 1, Offset: 0}},
+		{`"`, token.Token{Type: token.ILLEGAL, Literal: `"`, Line: 1, Column: 1, Offset: 0}},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+
+		tok := l.NextToken()
+		for tok.Type != token.ILLEGAL && tok.Type != token.EOF {
+			tok = l.NextToken()
+		}
+		if tok != tt.expected {
+			t.Errorf("tests[%d] - token wrong. expected=%+v, got=%+v", i, tt.expected, tok)
+		}
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Errorf("tests[%d] - token after ILLEGAL is not EOF. got=%+v", i, tok)
+		}
+	}
+}
+
+func TestNextTokenBlockComment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.TokenType
+	}{
+		{"/* comment */ 5;", []token.TokenType{token.INT, token.SEMICOLON, token.EOF}},
+		{"5 /* a\n \" // b\n */ * 2;", []token.TokenType{token.INT, token.ASTERISK, token.INT, token.SEMICOLON, token.EOF}},
+		{"/* a /* b */ 5;", []token.TokenType{token.INT, token.SEMICOLON, token.EOF}},
+		{"10 / 2;", []token.TokenType{token.INT, token.SLASH, token.INT, token.SEMICOLON, token.EOF}},
+		{"5; /* unterminated", []token.TokenType{token.INT, token.SEMICOLON, token.ILLEGAL, token.EOF}},
+		{"/*/", []token.TokenType{token.ILLEGAL, token.EOF}},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+
+		for j, expectedType := range tt.expected {
+			tok := l.NextToken()
+
+			if tok.Type != expectedType {
+				t.Fatalf("tests[%d][%d] - tokentype wrong. expected=%q, got=%q", i, j, expectedType, tok.Type)
+			}
+		}
+	}
+}
+
+func TestNextTokenFloat(t *testing.T) {
+	input := `
+	3.14;
+	12.;
+	.5;
+	`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, "3.14"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "12"},
+		{token.ILLEGAL, "."},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "."},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenRadix(t *testing.T) {
+	input := `0x1F; 0XFF; 0xG; 0b1010; 0b102;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "0x1F"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "0XFF"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "0xG"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "0b1010"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "0b102"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenDigitSeparator(t *testing.T) {
+	input := `1_000_000; 1_000.5; 1__0; 1_; 1_.5; _1;
+	0x1_0; 0b1_0; 0xF_F_F; 0x_1; 0x1_; 0xF__F; 0b_; 0x1_z;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "1_000_000"},
+		{token.SEMICOLON, ";"},
+		{token.FLOAT, "1_000.5"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "1__0"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "1_"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "1_.5"},
+		{token.SEMICOLON, ";"},
+		// 先頭がアンダースコアの場合は数値ではなく識別子として扱われる。
+		{token.IDENT, "_"},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		// 基数付きの数値リテラルでも、アンダースコアは英数字に挟まれていなければならない。
+		{token.INT, "0x1_0"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "0b1_0"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "0xF_F_F"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "0x_1"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "0x1_"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "0xF__F"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "0b_"},
+		{token.SEMICOLON, ";"},
+		// 英字の検証は構文解析に任せるため、アンダースコアの位置が正しければINTとして返す。
+		{token.INT, "0x1_z"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenString(t *testing.T) {
+	input := `"foobar"
+	"foo bar"
+	"a\nb"
+	"\""
+	"\\"
+	"\t\r"
+	"\q"
+	`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING, "foobar"},
+		{token.STRING, "foo bar"},
+		{token.STRING, "a\nb"},
+		{token.STRING, "\""},
+		{token.STRING, "\\"},
+		{token.STRING, "\t\r"},
+		{token.STRING, "\\q"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenUnicodeIdentifier(t *testing.T) {
+	input := `let 名前 = "値";
+	let café = 5;
+	let 😀 = 1;
+	`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedColumn  int
+	}{
+		{token.LET, "let", 1},
+		{token.IDENT, "名前", 5},
+		{token.ASSIGN, "=", 8},
+		{token.STRING, "値", 10},
+		{token.SEMICOLON, ";", 13},
+		{token.LET, "let", 2},
+		{token.IDENT, "café", 6},
+		{token.ASSIGN, "=", 11},
+		{token.INT, "5", 13},
+		{token.SEMICOLON, ";", 14},
+		{token.LET, "let", 2},
+		// 絵文字は識別子に使えない。
+		{token.ILLEGAL, "😀", 6},
+		{token.ASSIGN, "=", 8},
+		{token.INT, "1", 10},
+		{token.SEMICOLON, ";", 11},
+		{token.EOF, "", 2},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - column wrong. expected=%d, got=%d", i, tt.expectedColumn, tok.Column)
+		}
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	input := "let 名前 = \"値\";\n10 == 10;"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "名前"},
+		{token.ASSIGN, "="},
+		{token.STRING, "値"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "10"},
+		{token.EQ, "=="},
+		{token.INT, "10"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	// 1バイトずつしか読めないReaderでも、マルチバイト文字や先読みがバッファを跨いで動作することを確認する。
+	l := NewReader(iotest.OneByteReader(strings.NewReader(input)))
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+// 同じソースを指定したバイト数だけ繰り返し返すReader
+type repeatReader struct {
+	src       string
+	offset    int
+	remaining int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+
+	n := 0
+	for n < len(p) && r.remaining > 0 {
+		c := copy(p[n:], r.src[r.offset:])
+		if c > r.remaining {
+			c = r.remaining
+		}
+		n += c
+		r.remaining -= c
+		r.offset = (r.offset + c) % len(r.src)
+	}
+	return n, nil
+}
+
+// 入力全体をメモリに載せずに字句解析する。
+// 入力を1KBから1MBに増やしても、保持されるのはbufio.Readerと作業用のバッファだけで、
+// 入力全体の大きさの文字列やruneのスライスは確保されない。
+// B/opの増加分は各トークンのリテラル文字列で、使い終われば回収される。
+func BenchmarkNewReader(b *testing.B) {
+	src := "let add = fn(x, y) { x + y; };\nlet result = add(5, 10);\n"
+
+	for _, size := range []int{1 << 10, 1 << 20} {
+		b.Run(fmt.Sprintf("%dB", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l := NewReader(&repeatReader{src: src, remaining: size})
+				for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+				}
+			}
+		})
+	}
+}
@@ -1,19 +1,110 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
 
+	"local.packages/lexer"
+	"local.packages/parser"
 	"local.packages/repl"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		// ファイルを整形して終了するモード
+		os.Exit(formatCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
 	}
 	fmt.Printf("Hello %s! This is the Monkey programming language!\n", user.Username)
 	fmt.Printf("Feel free to tyep in commands\n")
+
+	if len(os.Args) > 1 && os.Args[1] == "--ast-json" {
+		// 構文解析したASTをJSONで出力するモード
+		repl.StartASTJSON(os.Stdin, os.Stdout)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "--stats" {
+		// 入力ごとに字句解析にかかった時間とアロケーションの回数を出力するモード
+		repl.StartStats(os.Stdin, os.Stdout)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "--lex" {
+		// 字句解析したトークン列を出力するモード
+		repl.StartLexer(os.Stdin, os.Stdout)
+		return
+	}
+
 	repl.Start(os.Stdin, os.Stdout)
 }
+
+// fmtサブコマンドの引数を解釈してファイルを整形し、終了コードを返す。
+// 整形結果はstdoutに出力し、-wが指定された場合はファイルを上書きする。
+func formatCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write result to the file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s fmt [-w] <file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 1
+	}
+
+	return formatFile(flags.Arg(0), *write, stdout, stderr)
+}
+
+// ファイルを読み込んで整形し、終了コードを返す。
+// 構文エラーがある場合は整形せず、stderrに出力して1を返す。
+func formatFile(path string, write bool, stdout, stderr io.Writer) int {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s\n", err)
+		return 1
+	}
+
+	p := parser.New(lexer.New(string(b)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		for _, msg := range p.Errors() {
+			fmt.Fprintf(stderr, "%s:%s\n", path, msg)
+		}
+		return 1
+	}
+
+	formatted := program.Format()
+	if !write {
+		fmt.Fprint(stdout, formatted)
+		return 0
+	}
+
+	if formatted == string(b) {
+		return 0
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s\n", err)
+		return 1
+	}
+	if err := os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+		fmt.Fprintf(stderr, "%s\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"local.packages/lexer"
+	"local.packages/parser"
+)
+
+func TestFormatCommand(t *testing.T) {
+	dir := t.TempDir()
+	input := "let add=fn(a,b){a+b};\nlet x = (1 + 2) * add(3, 4)\nif (x > 10) { puts(x) } else { puts(-x) }\n"
+	expected := "let add = fn(a, b) {\n  a + b;\n};\nlet x = (1 + 2) * add(3, 4);\nif (x > 10) {\n  puts(x);\n} else {\n  puts(-x);\n}\n"
+
+	path := filepath.Join(dir, "ok.monkey")
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := formatCommand([]string{path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code wrong. expected=0, got=%d, stderr=%q", code, stderr.String())
+	}
+	if stdout.String() != expected {
+		t.Errorf("stdout wrong.\nexpected=%q\ngot=%q", expected, stdout.String())
+	}
+
+	// 整形しても構文解析の結果は変わらない。
+	if parseString(t, stdout.String()) != parseString(t, input) {
+		t.Errorf("formatted source has different AST.\nexpected=%q\ngot=%q", parseString(t, input), parseString(t, stdout.String()))
+	}
+
+	// 標準出力に出すだけで、ファイルは書き換えない。
+	if b, _ := os.ReadFile(path); string(b) != input {
+		t.Errorf("file was modified without -w. got=%q", b)
+	}
+
+	stdout.Reset()
+	if code := formatCommand([]string{"-w", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code wrong with -w. expected=0, got=%d, stderr=%q", code, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout is not empty with -w. got=%q", stdout.String())
+	}
+	if b, _ := os.ReadFile(path); string(b) != expected {
+		t.Errorf("file not formatted with -w.\nexpected=%q\ngot=%q", expected, b)
+	}
+}
+
+func TestFormatCommandError(t *testing.T) {
+	dir := t.TempDir()
+	input := "let x = 1;\nlet = 2;\n"
+	path := filepath.Join(dir, "error.monkey")
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	tests := []struct {
+		args           []string
+		expectedStderr string
+	}{
+		{[]string{"-w", path}, path + ":2:5: expected next token to be IDENT, got = instead\n"},
+		{[]string{}, ""},
+		{[]string{path, path}, ""},
+		{[]string{filepath.Join(dir, "missing.monkey")}, ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := formatCommand(tt.args, &stdout, &stderr); code != 1 {
+			t.Errorf("formatCommand(%q) exit code wrong. expected=1, got=%d", tt.args, code)
+		}
+		if stdout.Len() != 0 {
+			t.Errorf("formatCommand(%q) stdout is not empty. got=%q", tt.args, stdout.String())
+		}
+		if stderr.Len() == 0 {
+			t.Errorf("formatCommand(%q) stderr is empty", tt.args)
+		}
+		if tt.expectedStderr != "" && stderr.String() != tt.expectedStderr {
+			t.Errorf("formatCommand(%q) stderr wrong. expected=%q, got=%q", tt.args, tt.expectedStderr, stderr.String())
+		}
+	}
+
+	// 構文エラーがある場合はファイルを書き換えない。
+	if b, _ := os.ReadFile(path); string(b) != input {
+		t.Errorf("file with errors was modified. got=%q", b)
+	}
+}
+
+// ソースを構文解析して、ASTの文字列表現を返す。
+func parseString(t *testing.T, input string) string {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors: %q", p.Errors())
+	}
+	return program.String()
+}
@@ -0,0 +1,717 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// rangeが返す配列の要素数の上限
+const maxRangeLength = 10_000_000
+
+// Builtins 組み込み関数の一覧
+// 名前で組み込み関数を探して呼び出し時の環境とともに実行するためのもので、
+// 識別子の評価からこの一覧を引く処理は評価器とともに追加する。
+var Builtins = []struct {
+	Name    string
+	Builtin *Builtin
+}{
+	{
+		"len",
+		// 文字列はバイト数ではなく文字（ルーン）の数を返す。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 1 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *Array:
+				return NewInteger(int64(len(arg.Elements)))
+			case *String:
+				return NewInteger(int64(utf8.RuneCountInString(arg.Value)))
+			default:
+				return NewError(TYPE_ERROR, "argument to `len` not supported, got %s", args[0].Type())
+			}
+		}},
+	},
+	{
+		"first",
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			arr, err := arrayArgument("first", args)
+			if err != nil {
+				return err
+			}
+
+			if len(arr.Elements) == 0 {
+				return NULL
+			}
+
+			return arr.Elements[0]
+		}},
+	},
+	{
+		"last",
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			arr, err := arrayArgument("last", args)
+			if err != nil {
+				return err
+			}
+
+			length := len(arr.Elements)
+			if length == 0 {
+				return NULL
+			}
+
+			return arr.Elements[length-1]
+		}},
+	},
+	{
+		"rest",
+		// 先頭を除いた要素を新しい配列として返し、元の配列は変更しない。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			arr, err := arrayArgument("rest", args)
+			if err != nil {
+				return err
+			}
+
+			length := len(arr.Elements)
+			if length == 0 {
+				return NULL
+			}
+
+			newElements := make([]Object, length-1)
+			copy(newElements, arr.Elements[1:length])
+
+			return &Array{Elements: newElements}
+		}},
+	},
+	{
+		"push",
+		// 末尾に要素を加えた新しい配列を返し、元の配列は変更しない。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 2 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if args[0].Type() != ARRAY_OBJ {
+				return NewError(TYPE_ERROR, "argument to `push` must be ARRAY, got %s", args[0].Type())
+			}
+
+			arr := args[0].(*Array)
+			length := len(arr.Elements)
+
+			newElements := make([]Object, length+1)
+			copy(newElements, arr.Elements)
+			newElements[length] = args[1]
+
+			return &Array{Elements: newElements}
+		}},
+	},
+	{
+		"puts",
+		// 引数を1つずつ環境の出力先へ書き出す。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			out := env.Output()
+			for _, arg := range args {
+				fmt.Fprintln(out, arg.Inspect())
+			}
+
+			return NULL
+		}},
+	},
+	{
+		"print",
+		// 引数をスペースで区切って改行せずに環境の出力先へ書き出し、書き出した文字列を返す。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			values := make([]string, 0, len(args))
+			for _, arg := range args {
+				values = append(values, arg.Inspect())
+			}
+
+			s := strings.Join(values, " ")
+			fmt.Fprint(env.Output(), s)
+
+			return &String{Value: s}
+		}},
+	},
+	{
+		"int",
+		// 整数はそのまま返し、浮動小数点数は小数部を切り捨て、文字列は10進数の整数として解釈する。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 1 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *Integer:
+				return arg
+			case *Float:
+				if math.IsInf(arg.Value, 0) || math.IsNaN(arg.Value) {
+					return NewError(VALUE_ERROR, "could not convert %s to integer", arg.Inspect())
+				}
+				return NewInteger(int64(arg.Value))
+			case *String:
+				value, err := strconv.ParseInt(arg.Value, 10, 64)
+				if err != nil {
+					return NewError(VALUE_ERROR, "could not parse %q as integer", arg.Value)
+				}
+				return NewInteger(value)
+			default:
+				return NewError(TYPE_ERROR, "argument to `int` not supported, got %s", args[0].Type())
+			}
+		}},
+	},
+	{
+		"str",
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 1 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			return &String{Value: args[0].Inspect()}
+		}},
+	},
+	{
+		"type",
+		// 型名はObjectTypeの定数値と一致させ、型による分岐に使えるようにする。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 1 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			return &String{Value: string(args[0].Type())}
+		}},
+	},
+	{
+		"keys",
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			hash, err := hashArgument("keys", args)
+			if err != nil {
+				return err
+			}
+
+			keys := make([]Object, 0, len(hash.Pairs))
+			for _, pair := range hash.OrderedPairs() {
+				keys = append(keys, pair.Key)
+			}
+
+			return &Array{Elements: keys}
+		}},
+	},
+	{
+		"values",
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			hash, err := hashArgument("values", args)
+			if err != nil {
+				return err
+			}
+
+			values := make([]Object, 0, len(hash.Pairs))
+			for _, pair := range hash.OrderedPairs() {
+				values = append(values, pair.Value)
+			}
+
+			return &Array{Elements: values}
+		}},
+	},
+	{
+		"contains",
+		// 配列は要素に==で等しいものがあるか、ハッシュはキーがあるか、文字列は部分文字列を含むかを返す。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 2 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+			}
+
+			switch coll := args[0].(type) {
+			case *Array:
+				for _, e := range coll.Elements {
+					if objectsEqual(e, args[1]) {
+						return TRUE
+					}
+				}
+				return FALSE
+			case *Hash:
+				key, ok := args[1].(Hashable)
+				if !ok {
+					return NewError(TYPE_ERROR, "unusable as hash key: %s", args[1].Type())
+				}
+				_, ok = coll.Pairs[key.HashKey()]
+				return NewBoolean(ok)
+			case *String:
+				substr, ok := args[1].(*String)
+				if !ok {
+					return NewError(TYPE_ERROR, "second argument to `contains` must be STRING when first is STRING, got %s", args[1].Type())
+				}
+				return NewBoolean(strings.Contains(coll.Value, substr.Value))
+			default:
+				return NewError(TYPE_ERROR, "argument to `contains` not supported, got %s", args[0].Type())
+			}
+		}},
+	},
+	{
+		"error",
+		// メッセージを持つUserErrorを返す。評価器はErrorを返した時点で評価を打ち切り、呼び出し元へ伝播する。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 1 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			msg, ok := args[0].(*String)
+			if !ok {
+				return NewError(TYPE_ERROR, "argument to `error` must be STRING, got %s", args[0].Type())
+			}
+
+			return NewError(USER_ERROR, "%s", msg.Value)
+		}},
+	},
+	{
+		"throw",
+		// 文字列を渡した場合はerrorと同じくUserErrorを返す。
+		// catchで捕捉したErrorを渡した場合は、そのErrorをそのまま返して送出し直す。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 1 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *Error:
+				return arg
+			case *String:
+				return NewError(USER_ERROR, "%s", arg.Value)
+			default:
+				return NewError(TYPE_ERROR, "argument to `throw` must be STRING or ERROR, got %s", args[0].Type())
+			}
+		}},
+	},
+	{
+		"range",
+		// range(stop)、range(start, stop)、range(start, stop, step)の形で呼び出し、
+		// startからstepずつ進めてstopの手前までの整数の配列を返す。
+		// stepが負の場合は減らしながらstopより大きい間の整数を返し、stopに届かない向きの場合は空の配列を返す。
+		// 要素数がmaxRangeLengthを超える場合はVALUE_ERRORを返す。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) < 1 || len(args) > 3 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1..3", len(args))
+			}
+
+			values := make([]int64, len(args))
+			for i, arg := range args {
+				integer, ok := arg.(*Integer)
+				if !ok {
+					return NewError(TYPE_ERROR, "arguments to `range` must be INTEGER, got %s", arg.Type())
+				}
+				values[i] = integer.Value
+			}
+
+			start, stop, step := int64(0), values[0], int64(1)
+			if len(values) >= 2 {
+				start, stop = values[0], values[1]
+			}
+			if len(values) == 3 {
+				step = values[2]
+			}
+			if step == 0 {
+				return NewError(VALUE_ERROR, "step argument to `range` must not be zero")
+			}
+
+			n := rangeLength(start, stop, step)
+			if n > maxRangeLength {
+				return NewError(VALUE_ERROR, "range is too large. got=%d elements, max=%d", n, maxRangeLength)
+			}
+
+			elements := make([]Object, 0, n)
+			for i, v := uint64(0), start; i < n; i, v = i+1, v+step {
+				elements = append(elements, NewInteger(v))
+			}
+
+			return &Array{Elements: elements}
+		}},
+	},
+	{
+		"map",
+		// 各要素に関数を適用した新しい配列を返す。関数がErrorを返した場合はそのまま返す。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 2 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if args[0].Type() != ARRAY_OBJ {
+				return NewError(TYPE_ERROR, "argument to `map` must be ARRAY, got %s", args[0].Type())
+			}
+
+			arr := args[0].(*Array)
+			mapped := make([]Object, 0, len(arr.Elements))
+			for _, e := range arr.Elements {
+				result := applyFunction(env, args[1], e)
+				if isError(result) {
+					return result
+				}
+				mapped = append(mapped, result)
+			}
+
+			return &Array{Elements: mapped}
+		}},
+	},
+	{
+		"filter",
+		// 述語がtruthyを返した要素だけの新しい配列を返す。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 2 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if args[0].Type() != ARRAY_OBJ {
+				return NewError(TYPE_ERROR, "argument to `filter` must be ARRAY, got %s", args[0].Type())
+			}
+
+			arr := args[0].(*Array)
+			filtered := []Object{}
+			for _, e := range arr.Elements {
+				result := applyFunction(env, args[1], e)
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					filtered = append(filtered, e)
+				}
+			}
+
+			return &Array{Elements: filtered}
+		}},
+	},
+	{
+		"reduce",
+		// 初期値から始めて、fn(acc, elem)の結果を次のaccとして畳み込む。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 3 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=3", len(args))
+			}
+			if args[0].Type() != ARRAY_OBJ {
+				return NewError(TYPE_ERROR, "argument to `reduce` must be ARRAY, got %s", args[0].Type())
+			}
+
+			arr := args[0].(*Array)
+			acc := args[1]
+			for _, e := range arr.Elements {
+				acc = applyFunction(env, args[2], acc, e)
+				if isError(acc) {
+					return acc
+				}
+			}
+
+			return acc
+		}},
+	},
+	{
+		"split",
+		// 区切り文字列が空の場合は1文字（ルーン）ずつに分割する。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 2 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if args[0].Type() != STRING_OBJ {
+				return NewError(TYPE_ERROR, "first argument to `split` must be STRING, got %s", args[0].Type())
+			}
+			if args[1].Type() != STRING_OBJ {
+				return NewError(TYPE_ERROR, "second argument to `split` must be STRING, got %s", args[1].Type())
+			}
+
+			parts := strings.Split(args[0].(*String).Value, args[1].(*String).Value)
+			elements := make([]Object, len(parts))
+			for i, part := range parts {
+				elements[i] = &String{Value: part}
+			}
+
+			return &Array{Elements: elements}
+		}},
+	},
+	{
+		"join",
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 2 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+			}
+			if args[0].Type() != ARRAY_OBJ {
+				return NewError(TYPE_ERROR, "first argument to `join` must be ARRAY, got %s", args[0].Type())
+			}
+			if args[1].Type() != STRING_OBJ {
+				return NewError(TYPE_ERROR, "second argument to `join` must be STRING, got %s", args[1].Type())
+			}
+
+			arr := args[0].(*Array)
+			parts := make([]string, len(arr.Elements))
+			for i, e := range arr.Elements {
+				str, ok := e.(*String)
+				if !ok {
+					return NewError(TYPE_ERROR, "elements of array passed to `join` must be STRING, got %s", e.Type())
+				}
+				parts[i] = str.Value
+			}
+
+			return &String{Value: strings.Join(parts, args[1].(*String).Value)}
+		}},
+	},
+	{
+		"format",
+		// 書式の%sを引数のInspectで、%dを整数の引数で、%%を%で置き換えた文字列を返す。
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) < 1 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want>=1", len(args))
+			}
+			if args[0].Type() != STRING_OBJ {
+				return NewError(TYPE_ERROR, "first argument to `format` must be STRING, got %s", args[0].Type())
+			}
+
+			s, err := formatString(args[0].(*String).Value, args[1:])
+			if err != nil {
+				return err
+			}
+
+			return &String{Value: s}
+		}},
+	},
+	{
+		"abs",
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			if len(args) != 1 {
+				return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *Integer:
+				if arg.Value >= 0 {
+					return arg
+				}
+				// 最小の整数の絶対値は整数で表せない。
+				if arg.Value == math.MinInt64 {
+					return NewError(VALUE_ERROR, "absolute value of %d overflows integer", arg.Value)
+				}
+				return NewInteger(-arg.Value)
+			case *Float:
+				return &Float{Value: math.Abs(arg.Value)}
+			default:
+				return NewError(TYPE_ERROR, "argument to `abs` not supported, got %s", args[0].Type())
+			}
+		}},
+	},
+	{
+		"min",
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			return extremum("min", args, -1)
+		}},
+	},
+	{
+		"max",
+		&Builtin{Fn: func(env *Environment, args ...Object) Object {
+			return extremum("max", args, 1)
+		}},
+	},
+}
+
+// GetBuiltinByName 名前に対応する組み込み関数を返す。存在しない場合はnilを返す。
+func GetBuiltinByName(name string) *Builtin {
+	for _, def := range Builtins {
+		if def.Name == name {
+			return def.Builtin
+		}
+	}
+
+	return nil
+}
+
+// 組み込み関数formatの書式に引数を当てはめた文字列を返す。
+// fmt.Sprintfに任せず、書式の指定子と引数の数や型が合わなければエラーを返す。
+func formatString(format string, args []Object) (string, *Error) {
+	var out strings.Builder
+	next := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			out.WriteByte(format[i])
+			continue
+		}
+
+		i++
+		if i == len(format) {
+			return "", NewError(VALUE_ERROR, "format %q ends with %%", format)
+		}
+
+		verb := format[i]
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+		if verb != 's' && verb != 'd' {
+			return "", NewError(VALUE_ERROR, "unknown verb %%%c in format %q", verb, format)
+		}
+
+		if next == len(args) {
+			return "", NewError(ARGUMENT_ERROR, "not enough arguments for format %q. got=%d", format, len(args))
+		}
+		arg := args[next]
+		next++
+
+		if verb == 'd' {
+			integer, ok := arg.(*Integer)
+			if !ok {
+				return "", NewError(TYPE_ERROR, "argument %d to `format` for %%d must be INTEGER, got %s", next, arg.Type())
+			}
+			out.WriteString(strconv.FormatInt(integer.Value, 10))
+			continue
+		}
+		out.WriteString(arg.Inspect())
+	}
+
+	if next != len(args) {
+		return "", NewError(ARGUMENT_ERROR, "too many arguments for format %q. got=%d, want=%d", format, len(args), next)
+	}
+
+	return out.String(), nil
+}
+
+// startからstepずつ進めてstopの手前までに含まれる整数の数を返す。
+// 差がint64に収まらない場合でもあふれないよう、uint64で計算する。
+func rangeLength(start, stop, step int64) uint64 {
+	var distance, stride uint64
+	switch {
+	case step > 0 && start < stop:
+		distance, stride = uint64(stop)-uint64(start), uint64(step)
+	case step < 0 && start > stop:
+		distance, stride = uint64(start)-uint64(stop), -uint64(step)
+	default:
+		return 0
+	}
+	return (distance-1)/stride + 1
+}
+
+// 組み込み関数minとmaxの本体。signが-1であれば最小、1であれば最大の引数を返す。
+// 等しい引数が複数あれば最初のものを返し、整数を浮動小数点数に昇格せずにそのまま返す。
+func extremum(name string, args []Object, sign int) Object {
+	if len(args) < 1 {
+		return NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want>=1", len(args))
+	}
+
+	var result Object
+	for _, arg := range args {
+		if _, ok := ToFloat(arg); !ok {
+			return NewError(TYPE_ERROR, "argument to `%s` not supported, got %s", name, arg.Type())
+		}
+		if result == nil || compareNumbers(arg, result) == sign {
+			result = arg
+		}
+	}
+
+	return result
+}
+
+// 数値aとbを比べ、aが小さければ-1、等しければ0、大きければ1を返す。
+// 整数どうしは精度を落とさないよう整数のまま比べ、それ以外は浮動小数点数に昇格して比べる。
+func compareNumbers(a, b Object) int {
+	if ai, ok := a.(*Integer); ok {
+		if bi, ok := b.(*Integer); ok {
+			switch {
+			case ai.Value < bi.Value:
+				return -1
+			case ai.Value > bi.Value:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	af, _ := ToFloat(a)
+	bf, _ := ToFloat(b)
+	switch {
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// 引数が配列1つであることを確かめて、その配列を返す。
+func arrayArgument(name string, args []Object) (*Array, *Error) {
+	if len(args) != 1 {
+		return nil, NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	if args[0].Type() != ARRAY_OBJ {
+		return nil, NewError(TYPE_ERROR, "argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+
+	return args[0].(*Array), nil
+}
+
+// 引数がハッシュ1つであることを確かめて、そのハッシュを返す。
+func hashArgument(name string, args []Object) (*Hash, *Error) {
+	if len(args) != 1 {
+		return nil, NewError(ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	if args[0].Type() != HASH_OBJ {
+		return nil, NewError(TYPE_ERROR, "argument to `%s` must be HASH, got %s", name, args[0].Type())
+	}
+
+	return args[0].(*Hash), nil
+}
+
+// 組み込み関数から関数を呼び出す。
+// 組み込み関数はその場で呼び出し、それ以外は環境に設定されたFunctionApplierに任せる。
+func applyFunction(env *Environment, fn Object, args ...Object) Object {
+	if builtin, ok := fn.(*Builtin); ok {
+		return builtin.Fn(env, args...)
+	}
+
+	if applier := env.FunctionApplier(); applier != nil {
+		return applier(fn, args)
+	}
+
+	return NewError(TYPE_ERROR, "not a function: %s", fn.Type())
+}
+
+// 2つの値が等しいかを返す。整数・浮動小数点数・文字列・真偽値・nullは値で比べ、
+// 配列・ハッシュ・関数などそれ以外の値は、要素を比べずに同じオブジェクトかどうかで比べる。
+// 整数と浮動小数点数は、浮動小数点数に昇格して値が等しければ等しいとする。
+func objectsEqual(a, b Object) bool {
+	if x, ok := ToFloat(a); ok {
+		if y, ok := ToFloat(b); ok && (a.Type() == FLOAT_OBJ || b.Type() == FLOAT_OBJ) {
+			return x == y
+		}
+	}
+
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		return a.Value == b.(*Integer).Value
+	case *String:
+		return a.Value == b.(*String).Value
+	case *Boolean:
+		return a.Value == b.(*Boolean).Value
+	case *Null:
+		return true
+	default:
+		return a == b
+	}
+}
+
+func isError(obj Object) bool {
+	if obj != nil {
+		return obj.Type() == ERROR_OBJ
+	}
+	return false
+}
+
+// NULLとfalseを偽、それ以外を真とみなす。
+func isTruthy(obj Object) bool {
+	switch obj := obj.(type) {
+	case *Null:
+		return false
+	case *Boolean:
+		return obj.Value
+	default:
+		return true
+	}
+}
@@ -0,0 +1,624 @@
+package object
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"local.packages/ast"
+)
+
+func TestBuiltinLen(t *testing.T) {
+	tests := []struct {
+		args     []Object
+		expected interface{}
+	}{
+		{[]Object{&String{Value: ""}}, 0},
+		{[]Object{&String{Value: "hello"}}, 5},
+		{[]Object{&String{Value: "あいう"}}, 3},
+		{[]Object{&Array{Elements: []Object{&Integer{Value: 1}, &Integer{Value: 2}, &Integer{Value: 3}}}}, 3},
+		{[]Object{&Array{}}, 0},
+		{[]Object{&Integer{Value: 1}}, "argument to `len` not supported, got INTEGER"},
+		{[]Object{&String{Value: "one"}, &String{Value: "two"}}, "wrong number of arguments. got=2, want=1"},
+		{[]Object{}, "wrong number of arguments. got=0, want=1"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, "len", callBuiltin("len", tt.args...), tt.expected)
+	}
+}
+
+func TestBuiltinArrayFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []Object
+		expected interface{}
+	}{
+		{"first", []Object{newIntegerArray(1, 2, 3)}, 1},
+		{"first", []Object{newIntegerArray()}, nil},
+		{"first", []Object{&Integer{Value: 1}}, "argument to `first` must be ARRAY, got INTEGER"},
+		{"last", []Object{newIntegerArray(1, 2, 3)}, 3},
+		{"last", []Object{newIntegerArray()}, nil},
+		{"last", []Object{&Integer{Value: 1}}, "argument to `last` must be ARRAY, got INTEGER"},
+		{"rest", []Object{newIntegerArray(1, 2, 3)}, []int{2, 3}},
+		{"rest", []Object{newIntegerArray(1)}, []int{}},
+		{"rest", []Object{newIntegerArray()}, nil},
+		{"rest", []Object{}, "wrong number of arguments. got=0, want=1"},
+		{"push", []Object{newIntegerArray(1, 2), &Integer{Value: 3}}, []int{1, 2, 3}},
+		{"push", []Object{newIntegerArray(), &Integer{Value: 1}}, []int{1}},
+		{"push", []Object{&Integer{Value: 1}, &Integer{Value: 1}}, "argument to `push` must be ARRAY, got INTEGER"},
+		{"push", []Object{newIntegerArray()}, "wrong number of arguments. got=1, want=2"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, tt.name, callBuiltin(tt.name, tt.args...), tt.expected)
+	}
+}
+
+func TestBuiltinArrayFunctionsDoNotModifyArgument(t *testing.T) {
+	arr := newIntegerArray(1, 2)
+
+	testBuiltinResult(t, "push", callBuiltin("push", arr, &Integer{Value: 3}), []int{1, 2, 3})
+	testBuiltinResult(t, "rest", callBuiltin("rest", arr), []int{2})
+
+	if arr.Inspect() != "[1, 2]" {
+		t.Errorf("original array was modified. got=%s", arr.Inspect())
+	}
+}
+
+func TestBuiltinPuts(t *testing.T) {
+	var out bytes.Buffer
+	env := NewEnvironment()
+	env.SetOutput(&out)
+
+	result := GetBuiltinByName("puts").Fn(NewEnclosedEnvironment(env), &String{Value: "a"}, &String{Value: "b"}, newIntegerArray(1, 2))
+	testBuiltinResult(t, "puts", result, nil)
+
+	if out.String() != "a\nb\n[1, 2]\n" {
+		t.Errorf("output wrong. got=%q", out.String())
+	}
+}
+
+func TestBuiltinPrint(t *testing.T) {
+	tests := []struct {
+		args     []Object
+		expected string
+	}{
+		{[]Object{&String{Value: "x"}}, "x"},
+		{[]Object{&String{Value: "a"}, NewInteger(1), newIntegerArray(2, 3)}, "a 1 [2, 3]"},
+		{[]Object{}, ""},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		env := NewEnvironment()
+		env.SetOutput(&out)
+
+		result := GetBuiltinByName("print").Fn(NewEnclosedEnvironment(env), tt.args...)
+		testBuiltinResult(t, "print", result, &String{Value: tt.expected})
+
+		if out.String() != tt.expected {
+			t.Errorf("output wrong. expected=%q, got=%q", tt.expected, out.String())
+		}
+	}
+}
+
+func TestBuiltinConversions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []Object
+		expected interface{}
+	}{
+		{"int", []Object{&String{Value: "10"}}, 10},
+		{"int", []Object{&String{Value: "-42"}}, -42},
+		{"int", []Object{&Integer{Value: 5}}, 5},
+		{"int", []Object{&String{Value: "abc"}}, `could not parse "abc" as integer`},
+		{"int", []Object{&String{Value: ""}}, `could not parse "" as integer`},
+		{"int", []Object{&Boolean{Value: true}}, "argument to `int` not supported, got BOOLEAN"},
+		{"int", []Object{newIntegerArray(1)}, "argument to `int` not supported, got ARRAY"},
+		{"int", []Object{}, "wrong number of arguments. got=0, want=1"},
+		{"str", []Object{&Integer{Value: 42}}, &String{Value: "42"}},
+		{"str", []Object{&Boolean{Value: false}}, &String{Value: "false"}},
+		{"str", []Object{&String{Value: "x"}}, &String{Value: "x"}},
+		{"str", []Object{newIntegerArray(1, 2)}, &String{Value: "[1, 2]"}},
+		{"str", []Object{}, "wrong number of arguments. got=0, want=1"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, tt.name, callBuiltin(tt.name, tt.args...), tt.expected)
+	}
+}
+
+func TestBuiltinIntFromFloat(t *testing.T) {
+	tests := []struct {
+		arg      Object
+		expected interface{}
+	}{
+		{&Float{Value: 2.9}, 2},
+		{&Float{Value: -2.9}, -2},
+		{&Float{Value: math.Inf(-1)}, "could not convert -Inf to integer"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, "int", callBuiltin("int", tt.arg), tt.expected)
+	}
+}
+
+func TestBuiltinType(t *testing.T) {
+	tests := []struct {
+		args     []Object
+		expected interface{}
+	}{
+		{[]Object{&Integer{Value: 5}}, &String{Value: INTEGER_OBJ}},
+		{[]Object{&String{Value: "x"}}, &String{Value: STRING_OBJ}},
+		{[]Object{&Boolean{Value: true}}, &String{Value: BOOLEAN_OBJ}},
+		{[]Object{newIntegerArray()}, &String{Value: ARRAY_OBJ}},
+		{[]Object{NULL}, &String{Value: NULL_OBJ}},
+		{[]Object{GetBuiltinByName("len")}, &String{Value: BUILTIN_OBJ}},
+		{[]Object{&Function{Body: &ast.BlockStatement{}, Env: NewEnvironment()}}, &String{Value: FUNCTION_OBJ}},
+		{[]Object{}, "wrong number of arguments. got=0, want=1"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, "type", callBuiltin("type", tt.args...), tt.expected)
+	}
+}
+
+func TestBuiltinKeysAndValues(t *testing.T) {
+	a := &String{Value: "a"}
+	b := &String{Value: "b"}
+	hash := NewHash()
+	hash.Set(b.HashKey(), HashPair{Key: b, Value: &Integer{Value: 1}})
+	hash.Set(a.HashKey(), HashPair{Key: a, Value: &Integer{Value: 2}})
+	hash.Set(b.HashKey(), HashPair{Key: b, Value: &Integer{Value: 3}})
+
+	// 挿入順に並び、再代入したキーは順序を維持したまま値だけが更新される。
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"keys", []string{"b", "a"}},
+		{"values", []string{"3", "2"}},
+	}
+
+	for _, tt := range tests {
+		result := callBuiltin(tt.name, hash)
+		arr, ok := result.(*Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T (%+v)", tt.name, result, result)
+			continue
+		}
+		if len(arr.Elements) != len(tt.expected) {
+			t.Errorf("%s: wrong number of elements. expected=%d, got=%d", tt.name, len(tt.expected), len(arr.Elements))
+			continue
+		}
+
+		for i, e := range tt.expected {
+			if arr.Elements[i].Inspect() != e {
+				t.Errorf("%s: element %d wrong. expected=%s, got=%s", tt.name, i, e, arr.Elements[i].Inspect())
+			}
+		}
+	}
+
+	testBuiltinResult(t, "keys", callBuiltin("keys", NewHash()), []int{})
+	testBuiltinResult(t, "keys", callBuiltin("keys", newIntegerArray()), "argument to `keys` must be HASH, got ARRAY")
+	testBuiltinResult(t, "values", callBuiltin("values"), "wrong number of arguments. got=0, want=1")
+}
+
+func TestBuiltinMap(t *testing.T) {
+	words := &Array{Elements: []Object{&String{Value: "a"}, &String{Value: "bc"}, &String{Value: "def"}}}
+	mixed := &Array{Elements: []Object{&String{Value: "a"}, &Integer{Value: 1}}}
+
+	tests := []struct {
+		args     []Object
+		expected interface{}
+	}{
+		{[]Object{words, GetBuiltinByName("len")}, []int{1, 2, 3}},
+		{[]Object{newIntegerArray(), GetBuiltinByName("len")}, []int{}},
+		{[]Object{mixed, GetBuiltinByName("len")}, "argument to `len` not supported, got INTEGER"},
+		{[]Object{words, &Integer{Value: 1}}, "not a function: INTEGER"},
+		{[]Object{&Integer{Value: 1}, GetBuiltinByName("len")}, "argument to `map` must be ARRAY, got INTEGER"},
+		{[]Object{words}, "wrong number of arguments. got=1, want=2"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, "map", callBuiltin("map", tt.args...), tt.expected)
+	}
+}
+
+func TestBuiltinContains(t *testing.T) {
+	hash := NewHash()
+	for _, key := range []Hashable{&String{Value: "one"}, NewInteger(2), TRUE} {
+		hash.Set(key.HashKey(), HashPair{Key: key.(Object), Value: NULL})
+	}
+	mixed := &Array{Elements: []Object{&String{Value: "a"}, NULL, FALSE}}
+
+	tests := []struct {
+		args     []Object
+		expected interface{}
+	}{
+		{[]Object{newIntegerArray(1, 2, 3), NewInteger(2)}, true},
+		{[]Object{newIntegerArray(1, 2, 3), NewInteger(4)}, false},
+		{[]Object{newIntegerArray(1, 2, 3), &String{Value: "2"}}, false},
+		{[]Object{newIntegerArray(), NewInteger(1)}, false},
+		{[]Object{mixed, &String{Value: "a"}}, true},
+		{[]Object{mixed, NULL}, true},
+		{[]Object{mixed, FALSE}, true},
+		{[]Object{mixed, TRUE}, false},
+		{[]Object{newIntegerArray(1, 2, 3), &Float{Value: 2.0}}, true},
+		{[]Object{newIntegerArray(1, 2, 3), &Float{Value: 2.5}}, false},
+		{[]Object{&Array{Elements: []Object{&Float{Value: 1.0}}}, NewInteger(1)}, true},
+		{[]Object{hash, &String{Value: "one"}}, true},
+		{[]Object{hash, NewInteger(2)}, true},
+		{[]Object{hash, TRUE}, true},
+		{[]Object{hash, &String{Value: "two"}}, false},
+		{[]Object{hash, newIntegerArray()}, "unusable as hash key: ARRAY"},
+		{[]Object{&String{Value: "hello"}, &String{Value: "ell"}}, true},
+		{[]Object{&String{Value: "hello"}, &String{Value: ""}}, true},
+		{[]Object{&String{Value: "hello"}, &String{Value: "world"}}, false},
+		{[]Object{&String{Value: "hello"}, NewInteger(1)}, "second argument to `contains` must be STRING when first is STRING, got INTEGER"},
+		{[]Object{NewInteger(1), NewInteger(1)}, "argument to `contains` not supported, got INTEGER"},
+		{[]Object{newIntegerArray()}, "wrong number of arguments. got=1, want=2"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, "contains", callBuiltin("contains", tt.args...), tt.expected)
+	}
+}
+
+func TestBuiltinRange(t *testing.T) {
+	tests := []struct {
+		args     []Object
+		expected interface{}
+	}{
+		{[]Object{NewInteger(5)}, []int{0, 1, 2, 3, 4}},
+		{[]Object{NewInteger(0)}, []int{}},
+		{[]Object{NewInteger(-1)}, []int{}},
+		{[]Object{NewInteger(2), NewInteger(5)}, []int{2, 3, 4}},
+		{[]Object{NewInteger(5), NewInteger(2)}, []int{}},
+		{[]Object{NewInteger(0), NewInteger(10), NewInteger(2)}, []int{0, 2, 4, 6, 8}},
+		{[]Object{NewInteger(0), NewInteger(9), NewInteger(3)}, []int{0, 3, 6}},
+		{[]Object{NewInteger(5), NewInteger(0), NewInteger(-2)}, []int{5, 3, 1}},
+		{[]Object{NewInteger(0), NewInteger(5), NewInteger(-1)}, []int{}},
+		{[]Object{NewInteger(0), NewInteger(-5), NewInteger(-1)}, []int{0, -1, -2, -3, -4}},
+		{[]Object{NewInteger(-1), NewInteger(-8), NewInteger(-3)}, []int{-1, -4, -7}},
+		{[]Object{NewInteger(math.MaxInt64 - 1), NewInteger(math.MaxInt64), NewInteger(2)}, []int{math.MaxInt64 - 1}},
+		{[]Object{NewInteger(math.MaxInt64 - 3), NewInteger(math.MaxInt64), NewInteger(2)}, []int{math.MaxInt64 - 3, math.MaxInt64 - 1}},
+		{[]Object{NewInteger(math.MinInt64 + 1), NewInteger(math.MinInt64), NewInteger(-2)}, []int{math.MinInt64 + 1}},
+		{[]Object{NewInteger(0), NewInteger(math.MaxInt64), NewInteger(math.MaxInt64)}, []int{0}},
+		{[]Object{NewInteger(math.MaxInt64), NewInteger(math.MinInt64), NewInteger(math.MinInt64)}, []int{math.MaxInt64, -1}},
+		{[]Object{NewInteger(maxRangeLength + 1)}, "range is too large. got=10000001 elements, max=10000000"},
+		{[]Object{NewInteger(math.MinInt64), NewInteger(math.MaxInt64)}, "range is too large. got=18446744073709551615 elements, max=10000000"},
+		{[]Object{NewInteger(math.MaxInt64), NewInteger(math.MinInt64), NewInteger(-1)}, "range is too large. got=18446744073709551615 elements, max=10000000"},
+		{[]Object{NewInteger(0), NewInteger(5), NewInteger(0)}, "step argument to `range` must not be zero"},
+		{[]Object{&String{Value: "5"}}, "arguments to `range` must be INTEGER, got STRING"},
+		{[]Object{}, "wrong number of arguments. got=0, want=1..3"},
+		{[]Object{NewInteger(1), NewInteger(2), NewInteger(3), NewInteger(4)}, "wrong number of arguments. got=4, want=1..3"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, "range", callBuiltin("range", tt.args...), tt.expected)
+	}
+}
+
+func TestBuiltinMapRange(t *testing.T) {
+	env := NewEnvironment()
+	// 評価器の代わりに、fn(x) { x * x }にあたる関数適用を設定する。
+	env.SetFunctionApplier(func(fn Object, args []Object) Object {
+		x := args[0].(*Integer).Value
+		return NewInteger(x * x)
+	})
+
+	r := GetBuiltinByName("range").Fn(env, NewInteger(3))
+	result := GetBuiltinByName("map").Fn(NewEnclosedEnvironment(env), r, &String{Value: "fn"})
+	testBuiltinResult(t, "map", result, []int{0, 1, 4})
+}
+
+func TestBuiltinMapWithFunctionApplier(t *testing.T) {
+	env := NewEnvironment()
+	// 評価器の代わりに、受け取った整数を2倍にする関数適用を設定する。
+	env.SetFunctionApplier(func(fn Object, args []Object) Object {
+		return &Integer{Value: args[0].(*Integer).Value * 2}
+	})
+
+	result := GetBuiltinByName("map").Fn(NewEnclosedEnvironment(env), newIntegerArray(1, 2, 3), &String{Value: "fn"})
+	testBuiltinResult(t, "map", result, []int{2, 4, 6})
+}
+
+func TestBuiltinFilterAndReduce(t *testing.T) {
+	env := NewEnvironment()
+	// 評価器の代わりに、引数が1つなら偶数かどうかを、2つなら和を返す関数適用を設定する。
+	env.SetFunctionApplier(func(fn Object, args []Object) Object {
+		if fn.Type() == ERROR_OBJ {
+			return fn
+		}
+		if len(args) == 1 {
+			return &Boolean{Value: args[0].(*Integer).Value%2 == 0}
+		}
+		return &Integer{Value: args[0].(*Integer).Value + args[1].(*Integer).Value}
+	})
+
+	fn := &String{Value: "fn"}
+	failing := &Error{Message: "failed"}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected interface{}
+	}{
+		{"filter", []Object{newIntegerArray(1, 2, 3, 4), fn}, []int{2, 4}},
+		{"filter", []Object{newIntegerArray(), fn}, []int{}},
+		{"filter", []Object{newIntegerArray(1, 2), failing}, "failed"},
+		{"filter", []Object{&Integer{Value: 1}, fn}, "argument to `filter` must be ARRAY, got INTEGER"},
+		{"filter", []Object{newIntegerArray(1)}, "wrong number of arguments. got=1, want=2"},
+		{"reduce", []Object{newIntegerArray(1, 2, 3), &Integer{Value: 0}, fn}, 6},
+		{"reduce", []Object{newIntegerArray(), &Integer{Value: 10}, fn}, 10},
+		{"reduce", []Object{newIntegerArray(1, 2), &Integer{Value: 0}, failing}, "failed"},
+		{"reduce", []Object{&Integer{Value: 1}, &Integer{Value: 0}, fn}, "argument to `reduce` must be ARRAY, got INTEGER"},
+		{"reduce", []Object{newIntegerArray(1), fn}, "wrong number of arguments. got=2, want=3"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, tt.name, GetBuiltinByName(tt.name).Fn(env, tt.args...), tt.expected)
+	}
+}
+
+func TestBuiltinFormat(t *testing.T) {
+	str := func(s string) *String { return &String{Value: s} }
+
+	tests := []struct {
+		args     []Object
+		expected interface{}
+	}{
+		{[]Object{str("%d+%d=%d"), NewInteger(1), NewInteger(2), NewInteger(3)}, str("1+2=3")},
+		{[]Object{str("%s is %d"), str("monkey"), NewInteger(5)}, str("monkey is 5")},
+		{[]Object{str("%s %s"), newIntegerArray(1, 2), TRUE}, str("[1, 2] true")},
+		{[]Object{str("100%%")}, str("100%")},
+		{[]Object{str("")}, str("")},
+		{[]Object{str("%d"), str("1")}, "argument 1 to `format` for %d must be INTEGER, got STRING"},
+		{[]Object{str("%s and %s"), str("a")}, `not enough arguments for format "%s and %s". got=1`},
+		{[]Object{str("%s"), str("a"), str("b")}, `too many arguments for format "%s". got=2, want=1`},
+		{[]Object{str("%x"), NewInteger(1)}, `unknown verb %x in format "%x"`},
+		{[]Object{str("50%")}, `format "50%" ends with %`},
+		{[]Object{NewInteger(1)}, "first argument to `format` must be STRING, got INTEGER"},
+		{[]Object{}, "wrong number of arguments. got=0, want>=1"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, "format", callBuiltin("format", tt.args...), tt.expected)
+	}
+}
+
+func TestBuiltinAbsMinMax(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []Object
+		expected interface{}
+	}{
+		{"abs", []Object{NewInteger(-5)}, 5},
+		{"abs", []Object{NewInteger(7)}, 7},
+		{"abs", []Object{NewInteger(0)}, 0},
+		{"abs", []Object{&Float{Value: -2.5}}, &Float{Value: 2.5}},
+		{"abs", []Object{NewInteger(math.MinInt64)}, "absolute value of -9223372036854775808 overflows integer"},
+		{"abs", []Object{&String{Value: "-1"}}, "argument to `abs` not supported, got STRING"},
+		{"abs", []Object{}, "wrong number of arguments. got=0, want=1"},
+		{"max", []Object{NewInteger(1), NewInteger(9), NewInteger(3)}, 9},
+		{"min", []Object{NewInteger(1), NewInteger(9), NewInteger(3)}, 1},
+		{"max", []Object{NewInteger(4)}, 4},
+		{"max", []Object{NewInteger(1), &Float{Value: 1.5}}, &Float{Value: 1.5}},
+		{"min", []Object{&Float{Value: 2.0}, NewInteger(2)}, &Float{Value: 2.0}},
+		{"min", []Object{NewInteger(2), &Float{Value: -0.5}, NewInteger(-1)}, -1},
+		{"max", []Object{NewInteger(math.MaxInt64), NewInteger(math.MaxInt64 - 1)}, math.MaxInt64},
+		{"max", []Object{NewInteger(1), &String{Value: "2"}}, "argument to `max` not supported, got STRING"},
+		{"min", []Object{}, "wrong number of arguments. got=0, want>=1"},
+		{"max", []Object{}, "wrong number of arguments. got=0, want>=1"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, tt.name, callBuiltin(tt.name, tt.args...), tt.expected)
+	}
+}
+
+func TestBuiltinSplitAndJoin(t *testing.T) {
+	strs := func(values ...string) *Array {
+		elements := []Object{}
+		for _, v := range values {
+			elements = append(elements, &String{Value: v})
+		}
+		return &Array{Elements: elements}
+	}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected interface{}
+	}{
+		{"split", []Object{&String{Value: "a,b,c"}, &String{Value: ","}}, strs("a", "b", "c")},
+		{"split", []Object{&String{Value: "abc"}, &String{Value: ","}}, strs("abc")},
+		{"split", []Object{&String{Value: "a,,b"}, &String{Value: ","}}, strs("a", "", "b")},
+		{"split", []Object{&String{Value: "あいう"}, &String{Value: ""}}, strs("あ", "い", "う")},
+		{"split", []Object{&Integer{Value: 1}, &String{Value: ","}}, "first argument to `split` must be STRING, got INTEGER"},
+		{"split", []Object{&String{Value: "a"}, &Integer{Value: 1}}, "second argument to `split` must be STRING, got INTEGER"},
+		{"split", []Object{&String{Value: "a"}}, "wrong number of arguments. got=1, want=2"},
+		{"join", []Object{strs("a", "b"), &String{Value: "-"}}, &String{Value: "a-b"}},
+		{"join", []Object{strs(), &String{Value: "-"}}, &String{Value: ""}},
+		{"join", []Object{newIntegerArray(1), &String{Value: "-"}}, "elements of array passed to `join` must be STRING, got INTEGER"},
+		{"join", []Object{&String{Value: "a"}, &String{Value: "-"}}, "first argument to `join` must be ARRAY, got STRING"},
+		{"join", []Object{strs("a"), &Integer{Value: 1}}, "second argument to `join` must be STRING, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		result := callBuiltin(tt.name, tt.args...)
+
+		arr, ok := tt.expected.(*Array)
+		if !ok {
+			testBuiltinResult(t, tt.name, result, tt.expected)
+			continue
+		}
+
+		if result.Inspect() != arr.Inspect() {
+			t.Errorf("%s: wrong result. expected=%s, got=%s", tt.name, arr.Inspect(), result.Inspect())
+		}
+		if got, ok := result.(*Array); !ok || len(got.Elements) != len(arr.Elements) {
+			t.Errorf("%s: wrong number of elements. expected=%d, got=%s", tt.name, len(arr.Elements), result.Inspect())
+		}
+	}
+}
+
+func TestBuiltinErrorAndThrow(t *testing.T) {
+	caught := NewError(ZERO_DIVISION_ERROR, "division by zero")
+
+	tests := []struct {
+		name            string
+		args            []Object
+		expectedKind    ErrorKind
+		expectedMessage string
+	}{
+		{"error", []Object{&String{Value: "boom"}}, USER_ERROR, "boom"},
+		{"error", []Object{&String{Value: "100%"}}, USER_ERROR, "100%"},
+		{"error", []Object{NewInteger(1)}, TYPE_ERROR, "argument to `error` must be STRING, got INTEGER"},
+		{"error", []Object{}, ARGUMENT_ERROR, "wrong number of arguments. got=0, want=1"},
+		{"throw", []Object{&String{Value: "boom"}}, USER_ERROR, "boom"},
+		{"throw", []Object{caught}, ZERO_DIVISION_ERROR, "division by zero"},
+		{"throw", []Object{NULL}, TYPE_ERROR, "argument to `throw` must be STRING or ERROR, got NULL"},
+		{"throw", []Object{&String{Value: "a"}, &String{Value: "b"}}, ARGUMENT_ERROR, "wrong number of arguments. got=2, want=1"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := callBuiltin(tt.name, tt.args...).(*Error)
+		if !ok {
+			t.Errorf("%s: object is not Error", tt.name)
+			continue
+		}
+		if errObj.Kind != tt.expectedKind {
+			t.Errorf("%s: wrong error kind. expected=%q, got=%q", tt.name, tt.expectedKind, errObj.Kind)
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("%s: wrong error message. expected=%q, got=%q", tt.name, tt.expectedMessage, errObj.Message)
+		}
+	}
+
+	// 捕捉したエラーを送出し直す場合は、同じErrorを返す。
+	if callBuiltin("throw", caught) != caught {
+		t.Errorf("throw does not return the caught error itself")
+	}
+}
+
+func TestBuiltinErrorKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []Object
+		expected ErrorKind
+	}{
+		{"len", []Object{}, ARGUMENT_ERROR},
+		{"len", []Object{NewInteger(1)}, TYPE_ERROR},
+		{"push", []Object{NewInteger(1), NewInteger(1)}, TYPE_ERROR},
+		{"int", []Object{&String{Value: "abc"}}, VALUE_ERROR},
+		{"contains", []Object{NewHash(), newIntegerArray()}, TYPE_ERROR},
+		{"range", []Object{NewInteger(0), NewInteger(1), NewInteger(0)}, VALUE_ERROR},
+		{"map", []Object{newIntegerArray(1), NewInteger(1)}, TYPE_ERROR},
+		{"join", []Object{&Array{Elements: []Object{NewInteger(1)}}, &String{Value: ","}}, TYPE_ERROR},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := callBuiltin(tt.name, tt.args...).(*Error)
+		if !ok {
+			t.Errorf("%s: object is not Error", tt.name)
+			continue
+		}
+		if errObj.Kind != tt.expected {
+			t.Errorf("%s: wrong error kind. expected=%q, got=%q (%s)", tt.name, tt.expected, errObj.Kind, errObj.Message)
+		}
+	}
+}
+
+func TestGetBuiltinByNameUnknown(t *testing.T) {
+	if builtin := GetBuiltinByName("unknown"); builtin != nil {
+		t.Errorf("GetBuiltinByName(\"unknown\") is not nil. got=%+v", builtin)
+	}
+}
+
+// 新しい環境で組み込み関数を呼び出す。
+func callBuiltin(name string, args ...Object) Object {
+	return GetBuiltinByName(name).Fn(NewEnvironment(), args...)
+}
+
+// 組み込み関数の戻り値を検証する。
+// expectedがnilの場合はNULL、stringの場合はエラーメッセージ、*Stringの場合は文字列として比較する。
+func testBuiltinResult(t *testing.T, name string, result Object, expected interface{}) {
+	t.Helper()
+
+	switch expected := expected.(type) {
+	case nil:
+		if result != NULL {
+			t.Errorf("%s: object is not NULL. got=%T (%+v)", name, result, result)
+		}
+	case int:
+		integer, ok := result.(*Integer)
+		if !ok {
+			t.Errorf("%s: object is not Integer. got=%T (%+v)", name, result, result)
+			return
+		}
+		if integer.Value != int64(expected) {
+			t.Errorf("%s: wrong value. expected=%d, got=%d", name, expected, integer.Value)
+		}
+	case bool:
+		boolean, ok := result.(*Boolean)
+		if !ok {
+			t.Errorf("%s: object is not Boolean. got=%T (%+v)", name, result, result)
+			return
+		}
+		if boolean.Value != expected {
+			t.Errorf("%s: wrong value. expected=%t, got=%t", name, expected, boolean.Value)
+		}
+	case []int:
+		arr, ok := result.(*Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T (%+v)", name, result, result)
+			return
+		}
+		if len(arr.Elements) != len(expected) {
+			t.Errorf("%s: wrong number of elements. expected=%d, got=%d", name, len(expected), len(arr.Elements))
+			return
+		}
+		for i, e := range expected {
+			testBuiltinResult(t, name, arr.Elements[i], e)
+		}
+	case *String:
+		str, ok := result.(*String)
+		if !ok {
+			t.Errorf("%s: object is not String. got=%T (%+v)", name, result, result)
+			return
+		}
+		if str.Value != expected.Value {
+			t.Errorf("%s: wrong value. expected=%q, got=%q", name, expected.Value, str.Value)
+		}
+	case *Float:
+		float, ok := result.(*Float)
+		if !ok {
+			t.Errorf("%s: object is not Float. got=%T (%+v)", name, result, result)
+			return
+		}
+		if float.Value != expected.Value {
+			t.Errorf("%s: wrong value. expected=%v, got=%v", name, expected.Value, float.Value)
+		}
+	case string:
+		errObj, ok := result.(*Error)
+		if !ok {
+			t.Errorf("%s: object is not Error. got=%T (%+v)", name, result, result)
+			return
+		}
+		if errObj.Message != expected {
+			t.Errorf("%s: wrong error message. expected=%q, got=%q", name, expected, errObj.Message)
+		}
+	}
+}
+
+func newIntegerArray(values ...int64) *Array {
+	elements := []Object{}
+	for _, v := range values {
+		elements = append(elements, &Integer{Value: v})
+	}
+
+	return &Array{Elements: elements}
+}
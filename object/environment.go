@@ -0,0 +1,177 @@
+package object
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// Environment 識別子と値の束縛を保持する環境
+// 関数呼び出しなどで内側のスコープを作る場合は、外側の環境をouterに持つ。
+type Environment struct {
+	store map[string]Object
+	// constで束縛した、再代入できない名前の集合。必要になるまで確保しない。
+	constants map[string]bool
+	outer     *Environment
+	out       io.Writer
+	applier   FunctionApplier
+}
+
+// FunctionApplier 関数オブジェクトを引数に適用して結果を返す
+// ユーザー定義関数の適用は評価器が担うためのもので、まだどこからも設定していない。
+// 設定されるまでは、mapやfilter、reduceにユーザー定義関数を渡すとエラーになる。
+type FunctionApplier func(fn Object, args []Object) Object
+
+// NewEnvironment 空の環境を生成する。
+func NewEnvironment() *Environment {
+	s := make(map[string]Object)
+	return &Environment{store: s, outer: nil}
+}
+
+// NewEnclosedEnvironment outerを外側のスコープとする環境を生成する。
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	env := NewEnvironment()
+	env.outer = outer
+	return env
+}
+
+// 関数呼び出しのたびに環境とmapを確保しないよう、使い終わった環境を再利用する。
+var environmentPool = sync.Pool{
+	New: func() interface{} {
+		return NewEnvironment()
+	},
+}
+
+// AcquireEnclosedEnvironment outerを外側のスコープとする環境を、再利用できる環境の中から取り出して返す。
+// 使い終わったらReleaseで戻す。
+func AcquireEnclosedEnvironment(outer *Environment) *Environment {
+	env := environmentPool.Get().(*Environment)
+	env.outer = outer
+	return env
+}
+
+// Release 環境を空にして、AcquireEnclosedEnvironmentで再利用できるように戻す。
+// クロージャとして返された関数など、環境を参照し続けるものがある場合は戻してはならない。
+func (e *Environment) Release() {
+	for name := range e.store {
+		delete(e.store, name)
+	}
+	e.constants = nil
+	e.outer = nil
+	e.out = nil
+	e.applier = nil
+	environmentPool.Put(e)
+}
+
+// Get 名前に束縛された値を返す。見つからなければ外側のスコープを探す。
+// 再帰の深い関数ではスコープが長く連なるため、再帰呼び出しではなくループで外側をたどる。
+func (e *Environment) Get(name string) (Object, bool) {
+	for env := e; env != nil; env = env.outer {
+		if obj, ok := env.store[name]; ok {
+			return obj, true
+		}
+	}
+	return nil, false
+}
+
+// Set 名前に値を束縛する。
+// このスコープで定数として束縛した名前であれば、束縛を変えずにエラーを返す。
+func (e *Environment) Set(name string, val Object) Object {
+	if e.constants[name] {
+		return newConstantError(name)
+	}
+
+	e.store[name] = val
+	return val
+}
+
+// SetConst 名前に値を再代入できない定数として束縛する。
+// このスコープで既に定数として束縛した名前であれば、束縛を変えずにエラーを返す。
+// 内側のスコープで同じ名前を束縛して、外側の定数を隠すことはできる。
+func (e *Environment) SetConst(name string, val Object) Object {
+	if e.constants[name] {
+		return newConstantError(name)
+	}
+
+	if e.constants == nil {
+		e.constants = make(map[string]bool)
+	}
+	e.constants[name] = true
+	e.store[name] = val
+	return val
+}
+
+// IsConst 名前が定数として束縛されていればtrueを返す。見つからなければ外側のスコープを探す。
+func (e *Environment) IsConst(name string) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			return env.constants[name]
+		}
+	}
+	return false
+}
+
+// Assign 既存の束縛に値を再代入する。名前を束縛しているスコープを外側へたどって探し、そのスコープの値を書き換える。
+// 束縛が見つからない場合と、定数に再代入しようとした場合はエラーを返す。
+func (e *Environment) Assign(name string, val Object) Object {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			return env.Set(name, val)
+		}
+	}
+	return NewError(NAME_ERROR, "identifier not found: %s", name)
+}
+
+// 定数への再代入のエラーを返す。
+func newConstantError(name string) *Error {
+	return NewError(TYPE_ERROR, "cannot reassign constant: %s", name)
+}
+
+// Dump 束縛されている名前と値の一覧を返す。
+// includeOuterがtrueの場合は外側のスコープも含め、同じ名前は内側の値を優先する。
+func (e *Environment) Dump(includeOuter bool) map[string]Object {
+	dump := make(map[string]Object)
+
+	if includeOuter && e.outer != nil {
+		dump = e.outer.Dump(true)
+	}
+	for name, val := range e.store {
+		dump[name] = val
+	}
+
+	return dump
+}
+
+// SetOutput putsなどの組み込み関数の出力先を設定する。
+func (e *Environment) SetOutput(w io.Writer) {
+	e.out = w
+}
+
+// Output 組み込み関数の出力先を返す。
+// 設定されていなければ外側のスコープの出力先を使い、どこにもなければ標準出力とする。
+func (e *Environment) Output() io.Writer {
+	if e.out != nil {
+		return e.out
+	}
+	if e.outer != nil {
+		return e.outer.Output()
+	}
+	return os.Stdout
+}
+
+// SetFunctionApplier mapなどの組み込み関数が関数を呼び出すときに使う処理を設定する。
+func (e *Environment) SetFunctionApplier(applier FunctionApplier) {
+	e.applier = applier
+}
+
+// FunctionApplier 関数を呼び出す処理を返す。
+// 設定されていなければ外側のスコープのものを使い、どこにもなければnilを返す。
+func (e *Environment) FunctionApplier() FunctionApplier {
+	if e.applier != nil {
+		return e.applier
+	}
+	if e.outer != nil {
+		return e.outer.FunctionApplier()
+	}
+	return nil
+}
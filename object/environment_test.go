@@ -0,0 +1,339 @@
+package object
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"local.packages/ast"
+	"local.packages/token"
+)
+
+func TestEnvironmentGetAndSet(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+	outer.Set("y", &Integer{Value: 2})
+
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("x", &Integer{Value: 10})
+
+	tests := []struct {
+		env      *Environment
+		name     string
+		expected int64
+	}{
+		{inner, "x", 10},
+		{inner, "y", 2},
+		{outer, "x", 1},
+	}
+
+	for _, tt := range tests {
+		obj, ok := tt.env.Get(tt.name)
+		if !ok {
+			t.Errorf("%s not found", tt.name)
+			continue
+		}
+		if obj.(*Integer).Value != tt.expected {
+			t.Errorf("%s wrong. expected=%d, got=%d", tt.name, tt.expected, obj.(*Integer).Value)
+		}
+	}
+
+	if _, ok := outer.Get("z"); ok {
+		t.Errorf("z should not be found")
+	}
+}
+
+func TestEnvironmentConst(t *testing.T) {
+	outer := NewEnvironment()
+	outer.SetConst("x", NewInteger(1))
+	outer.Set("y", NewInteger(2))
+	inner := NewEnclosedEnvironment(outer)
+
+	tests := []struct {
+		name     string
+		bind     func() Object
+		expected interface{}
+	}{
+		{"let", func() Object { return outer.Set("x", NewInteger(2)) }, "cannot reassign constant: x"},
+		{"const", func() Object { return outer.SetConst("x", NewInteger(2)) }, "cannot reassign constant: x"},
+		{"assign", func() Object { return outer.Assign("x", NewInteger(2)) }, "cannot reassign constant: x"},
+		{"assign from inner", func() Object { return inner.Assign("x", NewInteger(2)) }, "cannot reassign constant: x"},
+		{"assign variable from inner", func() Object { return inner.Assign("y", NewInteger(3)) }, 3},
+		{"assign unknown", func() Object { return inner.Assign("z", NewInteger(3)) }, "identifier not found: z"},
+	}
+
+	for _, tt := range tests {
+		switch result := tt.bind().(type) {
+		case *Error:
+			if msg, ok := tt.expected.(string); !ok || result.Message != msg {
+				t.Errorf("%s: error wrong. expected=%v, got=%q", tt.name, tt.expected, result.Message)
+			}
+		case *Integer:
+			if value, ok := tt.expected.(int); !ok || result.Value != int64(value) {
+				t.Errorf("%s: result wrong. expected=%v, got=%d", tt.name, tt.expected, result.Value)
+			}
+		default:
+			t.Errorf("%s: unexpected result. got=%T (%+v)", tt.name, result, result)
+		}
+	}
+
+	if obj, _ := outer.Get("x"); obj.(*Integer).Value != 1 {
+		t.Errorf("constant x was overwritten. got=%d", obj.(*Integer).Value)
+	}
+	if obj, _ := outer.Get("y"); obj.(*Integer).Value != 3 {
+		t.Errorf("y was not assigned in outer. got=%d", obj.(*Integer).Value)
+	}
+	if !inner.IsConst("x") || inner.IsConst("y") {
+		t.Errorf("IsConst wrong. x=%t, y=%t", inner.IsConst("x"), inner.IsConst("y"))
+	}
+
+	// 内側のスコープでは外側の定数と同じ名前を束縛できる。
+	if result := inner.Set("x", NewInteger(10)); isError(result) {
+		t.Errorf("shadowing constant failed: %s", result.Inspect())
+	}
+	if inner.IsConst("x") {
+		t.Errorf("shadowing variable should not be constant")
+	}
+}
+
+func TestEnvironmentDump(t *testing.T) {
+	global := NewEnvironment()
+	global.Set("x", &Integer{Value: 1})
+	global.Set("y", &Integer{Value: 2})
+
+	outer := NewEnclosedEnvironment(global)
+	outer.Set("y", &Integer{Value: 20})
+	outer.Set("z", &Integer{Value: 30})
+
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("z", &Integer{Value: 300})
+
+	tests := []struct {
+		env          *Environment
+		includeOuter bool
+		expected     map[string]int64
+	}{
+		{inner, false, map[string]int64{"z": 300}},
+		{inner, true, map[string]int64{"x": 1, "y": 20, "z": 300}},
+		{outer, true, map[string]int64{"x": 1, "y": 20, "z": 30}},
+		{global, true, map[string]int64{"x": 1, "y": 2}},
+		{NewEnvironment(), true, map[string]int64{}},
+	}
+
+	for i, tt := range tests {
+		dump := tt.env.Dump(tt.includeOuter)
+		if len(dump) != len(tt.expected) {
+			t.Errorf("tests[%d] wrong number of bindings. expected=%d, got=%d", i, len(tt.expected), len(dump))
+			continue
+		}
+
+		for name, expected := range tt.expected {
+			obj, ok := dump[name]
+			if !ok {
+				t.Errorf("tests[%d] %s not found", i, name)
+				continue
+			}
+			if obj.(*Integer).Value != expected {
+				t.Errorf("tests[%d] %s wrong. expected=%d, got=%d", i, name, expected, obj.(*Integer).Value)
+			}
+		}
+	}
+}
+
+func TestEnvironmentOutput(t *testing.T) {
+	env := NewEnvironment()
+	if env.Output() != os.Stdout {
+		t.Errorf("default output is not os.Stdout. got=%T", env.Output())
+	}
+
+	var out bytes.Buffer
+	env.SetOutput(&out)
+
+	inner := NewEnclosedEnvironment(env)
+	if inner.Output() != &out {
+		t.Errorf("enclosed environment does not inherit output. got=%T", inner.Output())
+	}
+}
+
+func TestAcquireAndReleaseEnvironment(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", NewInteger(1))
+
+	env := AcquireEnclosedEnvironment(outer)
+	env.Set("y", NewInteger(2))
+	env.SetConst("c", NewInteger(3))
+	if obj, ok := env.Get("x"); !ok || obj.(*Integer).Value != 1 {
+		t.Errorf("x is not found through outer. got=%v, %t", obj, ok)
+	}
+	env.Release()
+
+	if len(env.store) != 0 {
+		t.Errorf("released environment is not empty. got=%d", len(env.store))
+	}
+	if env.outer != nil {
+		t.Errorf("released environment still has outer")
+	}
+	if env.constants != nil {
+		t.Errorf("released environment still has constants")
+	}
+
+	// 戻した環境が再利用されても、外側の環境と束縛は持ち越さない。
+	reused := AcquireEnclosedEnvironment(nil)
+	if _, ok := reused.Get("x"); ok {
+		t.Errorf("reused environment has x")
+	}
+	if _, ok := reused.Get("y"); ok {
+		t.Errorf("reused environment has y")
+	}
+	reused.Release()
+
+	// 戻していない環境はそのまま使える。
+	closure := AcquireEnclosedEnvironment(outer)
+	closure.Set("z", NewInteger(3))
+	AcquireEnclosedEnvironment(outer).Release()
+	if obj, ok := closure.Get("z"); !ok || obj.(*Integer).Value != 3 {
+		t.Errorf("environment not released lost z. got=%v, %t", obj, ok)
+	}
+}
+
+// 評価器と同じく環境がヒープに確保されるよう、ベンチマークで作った環境を代入する。
+var environmentSink *Environment
+
+// 関数呼び出しごとに内側のスコープを作って引数を束縛する場合のアロケーションを比較する。
+func BenchmarkEnclosedEnvironment(b *testing.B) {
+	global := NewEnvironment()
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			env := NewEnclosedEnvironment(global)
+			env.Set("n", NewInteger(1))
+			environmentSink = env
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			env := AcquireEnclosedEnvironment(global)
+			env.Set("n", NewInteger(1))
+			environmentSink = env
+			env.Release()
+		}
+	})
+}
+
+// 再帰呼び出しの深い位置から、グローバルに束縛された名前を探す場合を想定する。
+func BenchmarkEnvironmentGet(b *testing.B) {
+	env := NewEnvironment()
+	env.Set("fib", NULL)
+	for i := 0; i < 30; i++ {
+		env = NewEnclosedEnvironment(env)
+		env.Set("n", NewInteger(int64(i)))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := env.Get("fib"); !ok {
+			b.Fatal("fib not found")
+		}
+	}
+}
+
+func TestEnvironmentSave(t *testing.T) {
+	env := NewEnvironment()
+	env.Set("n", NewInteger(-5))
+	env.Set("f", &Float{Value: -2.0})
+	env.Set("s", &String{Value: "a \"b\"\n"})
+	env.Set("arr", &Array{Elements: []Object{NewInteger(1), &String{Value: "two"}, TRUE, NULL}})
+	env.Set("len", &Builtin{})
+	env.SetConst("pi", NewInteger(3))
+
+	var buf bytes.Buffer
+	if err := env.Save(&buf); err != nil {
+		t.Fatalf("Save returned error: %s", err)
+	}
+
+	expected := `let arr = [1, "two", true, null];
+let f = -2.0;
+let n = -5;
+const pi = 3;
+let s = "a \"b\"\n";
+`
+	if buf.String() != expected {
+		t.Errorf("saved session wrong. expected=%q, got=%q", expected, buf.String())
+	}
+}
+
+func TestEnvironmentSaveFunction(t *testing.T) {
+	ident := func(name string) *ast.Identifier {
+		return &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+	block := func(stmts ...ast.Statement) *ast.BlockStatement {
+		return &ast.BlockStatement{Token: token.Token{Type: token.LBRACE, Literal: "{"}, Statements: stmts}
+	}
+	plus := func(left, right ast.Expression) ast.Expression {
+		return &ast.InfixExpression{Token: token.Token{Type: token.PLUS, Literal: "+"}, Left: left, Operator: "+", Right: right}
+	}
+
+	// fn add(x, y = "}", ...rest) { let s = "a \"b\" {"; let inner = fn(z) { z + x }; inner(y) + s }
+	inner := &ast.FunctionLiteral{
+		Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+		Parameters: []*ast.Identifier{ident("z")},
+		Body:       block(&ast.ExpressionStatement{Expression: plus(ident("z"), ident("x"))}),
+	}
+	env := NewEnvironment()
+	env.Set("add", &Function{
+		Name:       ident("add"),
+		Parameters: []*ast.Identifier{ident("x"), ident("y")},
+		Defaults:   map[string]ast.Expression{"y": &ast.StringLiteral{Value: "}"}},
+		Rest:       ident("rest"),
+		Body: block(
+			&ast.LetStatement{Name: ident("s"), Value: &ast.StringLiteral{Value: "a \"b\" {"}},
+			&ast.LetStatement{Name: ident("inner"), Value: inner},
+			&ast.ExpressionStatement{Expression: plus(
+				&ast.CallExpression{Function: ident("inner"), Arguments: []ast.Expression{ident("y")}},
+				ident("s"),
+			)},
+		),
+		Env: env,
+	})
+
+	var buf bytes.Buffer
+	if err := env.Save(&buf); err != nil {
+		t.Fatalf("Save returned error: %s", err)
+	}
+
+	expected := `let add = fn add(x, y = "}", ...rest) {
+  let s = "a \"b\" {";
+  let inner = fn(z) {
+    z + x;
+  };
+  inner(y) + s;
+};
+`
+	if buf.String() != expected {
+		t.Errorf("saved session wrong. expected=%q, got=%q", expected, buf.String())
+	}
+}
+
+func TestEnvironmentSaveHashOrder(t *testing.T) {
+	hash := NewHash()
+	for _, key := range []string{"z", "a", "m"} {
+		k := &String{Value: key}
+		hash.Set(k.HashKey(), HashPair{Key: k, Value: NewInteger(int64(len(hash.Pairs)))})
+	}
+	env := NewEnvironment()
+	env.Set("h", hash)
+
+	var buf bytes.Buffer
+	if err := env.Save(&buf); err != nil {
+		t.Fatalf("Save returned error: %s", err)
+	}
+
+	expected := "let h = {\"z\": 0, \"a\": 1, \"m\": 2};\n"
+	if buf.String() != expected {
+		t.Errorf("saved session wrong. expected=%q, got=%q", expected, buf.String())
+	}
+}
@@ -0,0 +1,9 @@
+module okuzawats.com/go/object
+
+go 1.19
+
+require local.packages/ast v0.0.0
+require local.packages/token v0.0.0
+
+replace local.packages/ast => ../ast
+replace local.packages/token => ../token
@@ -0,0 +1,640 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"local.packages/ast"
+)
+
+// ObjectType オブジェクトの種別を表すstringの別名
+type ObjectType string
+
+// オブジェクトの種別の定数定義のブロック
+const (
+	INTEGER_OBJ  = "INTEGER"
+	FLOAT_OBJ    = "FLOAT"
+	BOOLEAN_OBJ  = "BOOLEAN"
+	STRING_OBJ   = "STRING"
+	NULL_OBJ     = "NULL"
+	ERROR_OBJ    = "ERROR"
+	ARRAY_OBJ    = "ARRAY"
+	TUPLE_OBJ    = "TUPLE"
+	BUILTIN_OBJ  = "BUILTIN"
+	HASH_OBJ     = "HASH"
+	QUOTE_OBJ    = "QUOTE"
+	MACRO_OBJ    = "MACRO"
+	FUNCTION_OBJ = "FUNCTION"
+)
+
+// Object 評価時の値を表すオブジェクト
+type Object interface {
+	Type() ObjectType
+	Inspect() string
+	// Display REPLなどで人が読むための表示を返す。
+	// 文字列はそのまま表示するが、配列などの要素の文字列は引用符で囲み、文字列であることが分かるようにする。
+	Display() string
+}
+
+// コレクションの要素として表示する文字列を返す。文字列は引用符で囲み、エスケープが必要な文字をエスケープする。
+func displayElement(obj Object) string {
+	if s, ok := obj.(*String); ok {
+		return `"` + stringEscaper.Replace(s.Value) + `"`
+	}
+	return obj.Display()
+}
+
+// コレクションの要素をカンマで区切って表示する文字列を返す。
+func displayElements(elements []Object) string {
+	displays := make([]string, len(elements))
+	for i, e := range elements {
+		displays[i] = displayElement(e)
+	}
+	return strings.Join(displays, ", ")
+}
+
+// Integer 整数
+type Integer struct {
+	Value int64
+}
+
+func (i *Integer) Type() ObjectType {
+	return INTEGER_OBJ
+}
+
+func (i *Integer) Inspect() string {
+	return fmt.Sprintf("%d", i.Value)
+}
+
+func (i *Integer) Display() string {
+	return i.Inspect()
+}
+
+// キャッシュする小さな整数の範囲
+const (
+	minCachedInteger = -128
+	maxCachedInteger = 255
+)
+
+// 小さな整数のインスタンス。値がminCachedIntegerの整数を先頭に並べる。
+var cachedIntegers = func() []*Integer {
+	integers := make([]*Integer, maxCachedInteger-minCachedInteger+1)
+	for i := range integers {
+		integers[i] = &Integer{Value: int64(i + minCachedInteger)}
+	}
+	return integers
+}()
+
+// NewInteger valueを値とするIntegerを返す。
+// 小さな整数は生成済みのインスタンスを共有するため、返り値を書き換えてはならない。
+// また、同じ値のIntegerが同じインスタンスであるとは限らないため、値の比較にポインタを使ってはならない。
+func NewInteger(value int64) *Integer {
+	if minCachedInteger <= value && value <= maxCachedInteger {
+		return cachedIntegers[value-minCachedInteger]
+	}
+	return &Integer{Value: value}
+}
+
+// Float 浮動小数点数
+// 整数と浮動小数点数の混在した演算では、整数を浮動小数点数に昇格して計算し、結果をFloatとする。
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType {
+	return FLOAT_OBJ
+}
+
+// Inspect 整数と区別できるよう、小数部が無い値も"2.0"のように小数点を付けて表示する。
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'f', -1, 64)
+	if math.IsInf(f.Value, 0) || math.IsNaN(f.Value) || strings.Contains(s, ".") {
+		return s
+	}
+	return s + ".0"
+}
+
+func (f *Float) Display() string {
+	return f.Inspect()
+}
+
+// ToFloat 整数か浮動小数点数の値を、浮動小数点数に昇格して返す。
+// 数値でなければfalseを返す。現在はcontainsなどの値の比較で使い、中置演算での昇格は評価器とともに追加する。
+func ToFloat(obj Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return float64(obj.Value), true
+	case *Float:
+		return obj.Value, true
+	default:
+		return 0, false
+	}
+}
+
+// Boolean 真偽値
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType {
+	return BOOLEAN_OBJ
+}
+
+func (b *Boolean) Inspect() string {
+	return fmt.Sprintf("%t", b.Value)
+}
+
+func (b *Boolean) Display() string {
+	return b.Inspect()
+}
+
+// TRUE, FALSE 真偽値は2つしかないため、それぞれ唯一のインスタンスを共有する。
+var (
+	TRUE  = &Boolean{Value: true}
+	FALSE = &Boolean{Value: false}
+)
+
+// NewBoolean valueに対応する共有のBooleanを返す。
+func NewBoolean(value bool) *Boolean {
+	if value {
+		return TRUE
+	}
+	return FALSE
+}
+
+// String 文字列
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType {
+	return STRING_OBJ
+}
+
+func (s *String) Inspect() string {
+	return s.Value
+}
+
+func (s *String) Display() string {
+	return s.Value
+}
+
+// At 添字の位置の文字（ルーン）を1文字の文字列として返す。
+// 添字の数え方と範囲外の扱いはArray.Atと同じ。
+func (s *String) At(index int64) Object {
+	i, ok := resolveIndex(index, utf8.RuneCountInString(s.Value))
+	if !ok {
+		return NULL
+	}
+
+	for _, r := range s.Value {
+		if i == 0 {
+			return &String{Value: string(r)}
+		}
+		i--
+	}
+	return NULL
+}
+
+// Null 値が存在しないことを表す
+type Null struct{}
+
+func (n *Null) Type() ObjectType {
+	return NULL_OBJ
+}
+
+func (n *Null) Inspect() string {
+	return "null"
+}
+
+func (n *Null) Display() string {
+	return n.Inspect()
+}
+
+// NULL Nullは値を持たないため、唯一のインスタンスを共有する。
+var NULL = &Null{}
+
+// ErrorKind エラーの種別
+// プログラムからエラーの種類を区別できるよう、Errorごとに持つ。
+type ErrorKind string
+
+const (
+	// TYPE_ERROR 演算や関数に対応しない型の値を渡した
+	TYPE_ERROR ErrorKind = "TypeError"
+	// NAME_ERROR 束縛されていない識別子を参照した
+	NAME_ERROR ErrorKind = "NameError"
+	// ZERO_DIVISION_ERROR 0で割った
+	ZERO_DIVISION_ERROR ErrorKind = "ZeroDivisionError"
+	// ARGUMENT_ERROR 関数に渡した引数の数が合わない
+	ARGUMENT_ERROR ErrorKind = "ArgumentError"
+	// VALUE_ERROR 型は正しいが、値が受け付けられない
+	VALUE_ERROR ErrorKind = "ValueError"
+	// INDEX_ERROR 配列の範囲外の添字を指定した
+	INDEX_ERROR ErrorKind = "IndexError"
+	// USER_ERROR プログラムが組み込み関数のerrorやthrowで発生させた
+	USER_ERROR ErrorKind = "UserError"
+)
+
+// Error 評価中に発生したエラー
+type Error struct {
+	// エラーの種別。種別の分からないエラーでは空文字列。
+	Kind    ErrorKind
+	Message string
+	// エラーが発生した関数から順に、呼び出し元へたどった経路
+	Stack []Frame
+}
+
+// Frame 呼び出し経路の1つの関数呼び出し
+type Frame struct {
+	Function string // 呼び出された関数の名前。無名関数の場合は空文字列
+	Line     int    // 呼び出し位置の行番号
+	Column   int    // 呼び出し位置の列番号
+}
+
+func (f Frame) String() string {
+	name := f.Function
+	if name == "" {
+		name = "<anonymous>"
+	}
+
+	return fmt.Sprintf("%s (%d:%d)", name, f.Line, f.Column)
+}
+
+// NewError 種別とメッセージを持つErrorを生成する。メッセージはfmt.Sprintfの形式で組み立てる。
+func NewError(kind ErrorKind, format string, a ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
+}
+
+func (e *Error) Type() ObjectType {
+	return ERROR_OBJ
+}
+
+// Inspect 種別とメッセージに続けて、呼び出し経路を1フレームずつ表示する。
+func (e *Error) Inspect() string {
+	var out bytes.Buffer
+
+	out.WriteString("ERROR: ")
+	if e.Kind != "" {
+		out.WriteString(string(e.Kind) + ": ")
+	}
+	out.WriteString(e.Message)
+	for _, f := range e.Stack {
+		out.WriteString("\n\tat " + f.String())
+	}
+
+	return out.String()
+}
+
+func (e *Error) Display() string {
+	return e.Inspect()
+}
+
+// AddFrame エラーが関数呼び出しから戻るときに、その呼び出しを経路に加える。
+// 内側の呼び出しから順に加えることで、Stackはエラーの発生箇所に近い順に並ぶ。
+func (e *Error) AddFrame(f Frame) {
+	e.Stack = append(e.Stack, f)
+}
+
+// Array 配列
+type Array struct {
+	Elements []Object
+}
+
+func (ao *Array) Type() ObjectType {
+	return ARRAY_OBJ
+}
+
+func (ao *Array) Inspect() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, e := range ao.Elements {
+		elements = append(elements, e.Inspect())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+func (ao *Array) Display() string {
+	return "[" + displayElements(ao.Elements) + "]"
+}
+
+// Tuple "return a, b;"で関数から返される複数の値の組
+type Tuple struct {
+	Elements []Object
+}
+
+func (t *Tuple) Type() ObjectType {
+	return TUPLE_OBJ
+}
+
+func (t *Tuple) Inspect() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, e := range t.Elements {
+		elements = append(elements, e.Inspect())
+	}
+
+	out.WriteString("(")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+func (t *Tuple) Display() string {
+	return "(" + displayElements(t.Elements) + ")"
+}
+
+// At 添字の位置の要素を返す。
+// 負の添字は末尾から数え、-1が最後の要素を指す。範囲外の添字であればNULLを返す。
+// 添字式の評価からはまだ呼ばれておらず、arr[-1]のような式で使うのは評価器を追加してからになる。
+func (ao *Array) At(index int64) Object {
+	i, ok := resolveIndex(index, len(ao.Elements))
+	if !ok {
+		return NULL
+	}
+	return ao.Elements[i]
+}
+
+// 長さlengthの並びに対する添字を、先頭から数えた位置に直して返す。範囲外であればfalseを返す。
+func resolveIndex(index int64, length int) (int, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+	if index < 0 || index >= int64(length) {
+		return 0, false
+	}
+	return int(index), true
+}
+
+// BuiltinFunction 組み込み関数の実体
+// envは呼び出し時の環境で、出力先などの評価のコンテキストを参照するために使う。
+type BuiltinFunction func(env *Environment, args ...Object) Object
+
+// Builtin 組み込み関数
+type Builtin struct {
+	Fn BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType {
+	return BUILTIN_OBJ
+}
+
+func (b *Builtin) Inspect() string {
+	return "builtin function"
+}
+
+func (b *Builtin) Display() string {
+	return b.Inspect()
+}
+
+// HashKey ハッシュのキー
+// 種別を含めることで、値が同じでも種別の異なるキーが衝突しないようにする。
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Hashable ハッシュのキーとして使えるオブジェクト
+type Hashable interface {
+	HashKey() HashKey
+}
+
+// HashKey 整数の値をそのままキーの値とする。
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+// HashKey trueを1、falseを0としてキーの値とする。
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+
+	if b.Value {
+		value = 1
+	} else {
+		value = 0
+	}
+
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+// HashKey 文字列のFNV-1aハッシュをキーの値とする。
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+// HashPair ハッシュに格納するキーと値の組
+// Inspectなどで元のキーを表示できるよう、HashKeyではなくキーのオブジェクトを保持する。
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash ハッシュ
+// Goのmapは走査順が決まらないため、キーの挿入順をorderに記録し、走査はその順に行う。
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	order []HashKey
+}
+
+// NewHash 空のハッシュを生成して返す。
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+// Set キーに対応する組を格納する。
+// 新しいキーは挿入順の末尾に加え、既にあるキーは順序を維持したまま値だけを更新する。
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	if _, ok := h.Pairs[key]; !ok {
+		h.order = append(h.order, key)
+	}
+	h.Pairs[key] = pair
+}
+
+// OrderedPairs 格納された組を挿入順に並べて返す。
+// Setを介さずPairsに直接格納された組は、挿入順の組の後に並ぶ。
+func (h *Hash) OrderedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.Pairs))
+	seen := make(map[HashKey]bool, len(h.order))
+	for _, key := range h.order {
+		if pair, ok := h.Pairs[key]; ok && !seen[key] {
+			pairs = append(pairs, pair)
+			seen[key] = true
+		}
+	}
+	for key, pair := range h.Pairs {
+		if !seen[key] {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
+}
+
+func (h *Hash) Type() ObjectType {
+	return HASH_OBJ
+}
+
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, pair := range h.OrderedPairs() {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+func (h *Hash) Display() string {
+	pairs := []string{}
+	for _, pair := range h.OrderedPairs() {
+		pairs = append(pairs, displayElement(pair.Key)+": "+displayElement(pair.Value))
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Function ユーザー定義関数
+// 定義された環境を保持し、クロージャとして振る舞う。
+type Function struct {
+	// 関数の名前。評価器は関数を定義した環境を包む環境にこの名前で関数自身を束縛し、
+	// 本体から名前で再帰呼び出しできるようにする。無名関数であればnil。
+	Name       *ast.Identifier
+	Parameters []*ast.Identifier
+	// 仮引数の名前とデフォルト値の式のマッピング
+	// デフォルト値は関数の定義時ではなく、呼び出し時に仮引数を束縛する環境で評価する。
+	Defaults map[string]ast.Expression
+	// 仮引数に束縛されなかった残りの引数を、配列として受け取る仮引数
+	Rest *ast.Identifier
+	Body *ast.BlockStatement
+	Env  *Environment
+}
+
+func (f *Function) Type() ObjectType {
+	return FUNCTION_OBJ
+}
+
+func (f *Function) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range f.Parameters {
+		if value, ok := f.Defaults[p.Value]; ok {
+			params = append(params, p.String()+" = "+value.String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
+	}
+
+	out.WriteString("fn")
+	if f.Name != nil {
+		out.WriteString(" " + f.Name.String())
+	}
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(f.Body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
+
+func (f *Function) Display() string {
+	return f.Inspect()
+}
+
+// CheckArity n個の実引数で関数を呼び出せるかを確かめ、呼び出せなければエラーを返す。
+// デフォルト値を持つ仮引数は省略でき、残りの引数を受け取る仮引数があれば実引数の数に上限はない。
+// 仮引数を束縛する前に呼び出して未束縛の仮引数が残らないようにするためのもので、
+// applyFunctionからの呼び出しは評価器とともに追加する。
+func (f *Function) CheckArity(n int) *Error {
+	max := len(f.Parameters)
+	min := max - len(f.Defaults)
+
+	switch {
+	case f.Rest != nil && n < min:
+		return NewError(ARGUMENT_ERROR, "wrong number of arguments: want>=%d, got=%d", min, n)
+	case f.Rest != nil:
+		return nil
+	case min == max && n != max:
+		return NewError(ARGUMENT_ERROR, "wrong number of arguments: want=%d, got=%d", max, n)
+	case n < min || n > max:
+		return NewError(ARGUMENT_ERROR, "wrong number of arguments: want=%d..%d, got=%d", min, max, n)
+	default:
+		return nil
+	}
+}
+
+// Quote quoteされ、評価されずにオブジェクトとして扱われるASTノード
+type Quote struct {
+	Node ast.Node
+}
+
+func (q *Quote) Type() ObjectType {
+	return QUOTE_OBJ
+}
+
+func (q *Quote) Inspect() string {
+	return "QUOTE(" + q.Node.String() + ")"
+}
+
+func (q *Quote) Display() string {
+	return q.Inspect()
+}
+
+// Macro マクロ
+// 関数と同じく仮引数と本体、定義された環境を持つが、引数は評価せずにQuoteとして受け取る。
+// マクロ定義を集めるDefineMacrosと展開するExpandMacrosはまだ無く、現在は値として保持するだけ。
+type Macro struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (m *Macro) Type() ObjectType {
+	return MACRO_OBJ
+}
+
+func (m *Macro) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range m.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("macro")
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(m.Body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
+
+func (m *Macro) Display() string {
+	return m.Inspect()
+}
@@ -0,0 +1,343 @@
+package object
+
+import (
+	"math"
+	"testing"
+
+	"local.packages/ast"
+	"local.packages/token"
+)
+
+func TestStringHashKey(t *testing.T) {
+	hello1 := &String{Value: "Hello World"}
+	hello2 := &String{Value: "Hello World"}
+	diff1 := &String{Value: "My name is johnny"}
+	diff2 := &String{Value: "My name is johnny"}
+
+	if hello1.HashKey() != hello2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if diff1.HashKey() != diff2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if hello1.HashKey() == diff1.HashKey() {
+		t.Errorf("strings with different content have same hash keys")
+	}
+}
+
+func TestHashKeyDifferentTypes(t *testing.T) {
+	one := &Integer{Value: 1}
+	yes := &Boolean{Value: true}
+	zero := &Integer{Value: 0}
+	no := &Boolean{Value: false}
+
+	if one.HashKey() == yes.HashKey() {
+		t.Errorf("integer 1 and true have same hash keys")
+	}
+
+	if zero.HashKey() == no.HashKey() {
+		t.Errorf("integer 0 and false have same hash keys")
+	}
+
+	if one.HashKey() != (&Integer{Value: 1}).HashKey() {
+		t.Errorf("integers with same value have different hash keys")
+	}
+}
+
+func TestNewInteger(t *testing.T) {
+	tests := []struct {
+		value  int64
+		shared bool
+	}{
+		{-129, false},
+		{-128, true},
+		{0, true},
+		{255, true},
+		{256, false},
+	}
+
+	for _, tt := range tests {
+		a := NewInteger(tt.value)
+		b := NewInteger(tt.value)
+
+		if a.Value != tt.value || b.Value != tt.value {
+			t.Errorf("NewInteger(%d) has wrong value. got=%d, %d", tt.value, a.Value, b.Value)
+		}
+		if (a == b) != tt.shared {
+			t.Errorf("NewInteger(%d) shared wrong. want %t, got=%t", tt.value, tt.shared, a == b)
+		}
+	}
+}
+
+func TestNewBoolean(t *testing.T) {
+	if NewBoolean(true) != TRUE || !TRUE.Value {
+		t.Errorf("NewBoolean(true) is not TRUE")
+	}
+	if NewBoolean(false) != FALSE || FALSE.Value {
+		t.Errorf("NewBoolean(false) is not FALSE")
+	}
+}
+
+// 小さな整数を繰り返し生成したときのアロケーションを比較する。
+func BenchmarkNewInteger(b *testing.B) {
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		var sink *Integer
+		for i := 0; i < b.N; i++ {
+			sink = NewInteger(int64(i % 100))
+		}
+		_ = sink
+	})
+
+	b.Run("literal", func(b *testing.B) {
+		b.ReportAllocs()
+		var sink *Integer
+		for i := 0; i < b.N; i++ {
+			sink = &Integer{Value: int64(i % 100)}
+		}
+		_ = sink
+	})
+}
+
+func TestErrorInspectWithStack(t *testing.T) {
+	err := &Error{Message: "division by zero"}
+	if err.Inspect() != "ERROR: division by zero" {
+		t.Errorf("err.Inspect() wrong. got=%q", err.Inspect())
+	}
+
+	// inner関数の中でエラーが発生し、outer、無名関数の順に呼び出し元へ戻った場合
+	err.AddFrame(Frame{Function: "inner", Line: 2, Column: 10})
+	err.AddFrame(Frame{Function: "outer", Line: 5, Column: 3})
+	err.AddFrame(Frame{Line: 8, Column: 1})
+
+	expected := "ERROR: division by zero\n" +
+		"\tat inner (2:10)\n" +
+		"\tat outer (5:3)\n" +
+		"\tat <anonymous> (8:1)"
+	if err.Inspect() != expected {
+		t.Errorf("err.Inspect() wrong.\nexpected=%q\ngot=%q", expected, err.Inspect())
+	}
+}
+
+func TestFloat(t *testing.T) {
+	tests := []struct {
+		obj      Object
+		inspect  string
+		value    float64
+		isNumber bool
+	}{
+		{&Float{Value: 2.5}, "2.5", 2.5, true},
+		{&Float{Value: 2}, "2.0", 2, true},
+		{&Float{Value: -0.125}, "-0.125", -0.125, true},
+		{&Float{Value: 1e21}, "1000000000000000000000.0", 1e21, true},
+		{&Float{Value: math.Inf(1)}, "+Inf", math.Inf(1), true},
+		{NewInteger(3), "3", 3, true},
+		{&String{Value: "1.5"}, "1.5", 0, false},
+	}
+
+	for _, tt := range tests {
+		if tt.obj.Inspect() != tt.inspect {
+			t.Errorf("Inspect wrong. expected=%q, got=%q", tt.inspect, tt.obj.Inspect())
+		}
+		value, ok := ToFloat(tt.obj)
+		if ok != tt.isNumber || value != tt.value {
+			t.Errorf("ToFloat(%s) wrong. expected=%v, %t, got=%v, %t", tt.obj.Inspect(), tt.value, tt.isNumber, value, ok)
+		}
+	}
+}
+
+func TestHashInspectInInsertionOrder(t *testing.T) {
+	b := &String{Value: "b"}
+	a := &String{Value: "a"}
+	hash := NewHash()
+	hash.Set(b.HashKey(), HashPair{Key: b, Value: NewInteger(1)})
+	hash.Set(a.HashKey(), HashPair{Key: a, Value: NewInteger(2)})
+
+	if hash.Inspect() != "{b: 1, a: 2}" {
+		t.Errorf("hash.Inspect() wrong. got=%q", hash.Inspect())
+	}
+
+	// 既にあるキーへの再代入は順序を変えない。
+	hash.Set(b.HashKey(), HashPair{Key: b, Value: NewInteger(3)})
+	if hash.Inspect() != "{b: 3, a: 2}" {
+		t.Errorf("hash.Inspect() after reassignment wrong. got=%q", hash.Inspect())
+	}
+}
+
+func TestIndexAt(t *testing.T) {
+	arr := newIntegerArray(1, 2, 3)
+	str := &String{Value: "aあc"}
+
+	tests := []struct {
+		obj      interface{ At(int64) Object }
+		index    int64
+		expected string
+	}{
+		{arr, 0, "1"},
+		{arr, 2, "3"},
+		{arr, -1, "3"},
+		{arr, -3, "1"},
+		{arr, 3, "null"},
+		{arr, -4, "null"},
+		{&Array{}, -1, "null"},
+		{str, 1, "あ"},
+		{str, -1, "c"},
+		{str, -3, "a"},
+		{str, 3, "null"},
+		{str, -4, "null"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.obj.At(tt.index).Inspect(); got != tt.expected {
+			t.Errorf("At(%d) wrong. expected=%q, got=%q", tt.index, tt.expected, got)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	a := &String{Value: "a"}
+	nested := NewHash()
+	nested.Set(a.HashKey(), HashPair{Key: a, Value: &Array{Elements: []Object{&String{Value: "x\"y"}, NULL}}})
+	nested.Set(NewInteger(1).HashKey(), HashPair{Key: NewInteger(1), Value: &String{Value: "b"}})
+
+	tests := []struct {
+		obj     Object
+		display string
+		inspect string
+	}{
+		{a, `a`, `a`},
+		{&Array{Elements: []Object{a, &String{Value: "b"}}}, `["a", "b"]`, `[a, b]`},
+		{&Array{Elements: []Object{NewInteger(1), &Float{Value: 2}, TRUE}}, `[1, 2.0, true]`, `[1, 2.0, true]`},
+		{&Array{Elements: []Object{&Array{Elements: []Object{&String{Value: "a\nb"}}}}}, `[["a\nb"]]`, "[[a\nb]]"},
+		{nested, `{"a": ["x\"y", null], 1: "b"}`, `{a: [x"y, null], 1: b}`},
+		{&Tuple{Elements: []Object{a, NewInteger(2)}}, `("a", 2)`, `(a, 2)`},
+		{NewError(TYPE_ERROR, "bad"), `ERROR: TypeError: bad`, `ERROR: TypeError: bad`},
+	}
+
+	for _, tt := range tests {
+		if tt.obj.Display() != tt.display {
+			t.Errorf("Display wrong. expected=%q, got=%q", tt.display, tt.obj.Display())
+		}
+		if tt.obj.Inspect() != tt.inspect {
+			t.Errorf("Inspect wrong. expected=%q, got=%q", tt.inspect, tt.obj.Inspect())
+		}
+	}
+}
+
+func TestFunctionCheckArity(t *testing.T) {
+	ident := func(name string) *ast.Identifier {
+		return &ast.Identifier{Value: name}
+	}
+	defaults := map[string]ast.Expression{"b": &ast.IntegerLiteral{Value: 2}}
+
+	tests := []struct {
+		fn       *Function
+		args     int
+		expected string
+	}{
+		{&Function{Parameters: []*ast.Identifier{ident("a"), ident("b")}}, 2, ""},
+		{&Function{Parameters: []*ast.Identifier{ident("a"), ident("b")}}, 1, "wrong number of arguments: want=2, got=1"},
+		{&Function{Parameters: []*ast.Identifier{ident("a"), ident("b")}}, 3, "wrong number of arguments: want=2, got=3"},
+		{&Function{}, 0, ""},
+		{&Function{}, 1, "wrong number of arguments: want=0, got=1"},
+		{&Function{Parameters: []*ast.Identifier{ident("a"), ident("b")}, Defaults: defaults}, 1, ""},
+		{&Function{Parameters: []*ast.Identifier{ident("a"), ident("b")}, Defaults: defaults}, 0, "wrong number of arguments: want=1..2, got=0"},
+		{&Function{Parameters: []*ast.Identifier{ident("a"), ident("b")}, Defaults: defaults}, 3, "wrong number of arguments: want=1..2, got=3"},
+		{&Function{Parameters: []*ast.Identifier{ident("a")}, Rest: ident("r")}, 5, ""},
+		{&Function{Parameters: []*ast.Identifier{ident("a")}, Rest: ident("r")}, 0, "wrong number of arguments: want>=1, got=0"},
+		{&Function{Rest: ident("r")}, 0, ""},
+	}
+
+	for i, tt := range tests {
+		err := tt.fn.CheckArity(tt.args)
+		if tt.expected == "" {
+			if err != nil {
+				t.Errorf("tests[%d] with %d arguments: unexpected error %q", i, tt.args, err.Message)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("tests[%d] with %d arguments: no error", i, tt.args)
+			continue
+		}
+		if err.Kind != ARGUMENT_ERROR || err.Message != tt.expected {
+			t.Errorf("tests[%d] with %d arguments: wrong error. expected=%q, got=%s", i, tt.args, tt.expected, err.Inspect())
+		}
+	}
+}
+
+func TestTupleInspect(t *testing.T) {
+	tuple := &Tuple{Elements: []Object{NewInteger(1), &String{Value: "two"}, TRUE}}
+	if tuple.Type() != TUPLE_OBJ {
+		t.Errorf("tuple.Type() wrong. got=%q", tuple.Type())
+	}
+	if tuple.Inspect() != "(1, two, true)" {
+		t.Errorf("tuple.Inspect() wrong. got=%q", tuple.Inspect())
+	}
+}
+
+func TestErrorInspectWithKind(t *testing.T) {
+	err := NewError(ZERO_DIVISION_ERROR, "division by zero: %d / %d", 1, 0)
+	if err.Kind != ZERO_DIVISION_ERROR {
+		t.Errorf("err.Kind wrong. got=%q", err.Kind)
+	}
+	if err.Inspect() != "ERROR: ZeroDivisionError: division by zero: 1 / 0" {
+		t.Errorf("err.Inspect() wrong. got=%q", err.Inspect())
+	}
+
+	err.AddFrame(Frame{Function: "div", Line: 1, Column: 5})
+	if err.Inspect() != "ERROR: ZeroDivisionError: division by zero: 1 / 0\n\tat div (1:5)" {
+		t.Errorf("err.Inspect() wrong. got=%q", err.Inspect())
+	}
+}
+
+func TestFunctionInspect(t *testing.T) {
+	x := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"}
+	y := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "y"}, Value: "y"}
+	body := &ast.BlockStatement{
+		Token: token.Token{Type: token.LBRACE, Literal: "{"},
+		Statements: []ast.Statement{
+			&ast.ExpressionStatement{Token: x.Token, Expression: x},
+		},
+	}
+
+	function := &Function{
+		Parameters: []*ast.Identifier{x, y},
+		Defaults: map[string]ast.Expression{
+			"y": &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "5"}, Value: 5},
+		},
+		Body: body,
+		Env:  NewEnvironment(),
+	}
+
+	if function.Inspect() != "fn(x, y = 5) {\nx\n}" {
+		t.Errorf("function.Inspect() wrong. got=%q", function.Inspect())
+	}
+
+	function.Name = &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "f"}, Value: "f"}
+	if function.Inspect() != "fn f(x, y = 5) {\nx\n}" {
+		t.Errorf("named function.Inspect() wrong. got=%q", function.Inspect())
+	}
+}
+
+func TestQuoteAndMacroInspect(t *testing.T) {
+	x := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"}
+	body := &ast.BlockStatement{
+		Token: token.Token{Type: token.LBRACE, Literal: "{"},
+		Statements: []ast.Statement{
+			&ast.ExpressionStatement{Token: x.Token, Expression: x},
+		},
+	}
+
+	quote := &Quote{Node: x}
+	if quote.Inspect() != "QUOTE(x)" {
+		t.Errorf("quote.Inspect() wrong. got=%q", quote.Inspect())
+	}
+
+	macro := &Macro{Parameters: []*ast.Identifier{x}, Body: body, Env: NewEnvironment()}
+	if macro.Inspect() != "macro(x) {\nx\n}" {
+		t.Errorf("macro.Inspect() wrong. got=%q", macro.Inspect())
+	}
+}
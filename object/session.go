@@ -0,0 +1,102 @@
+package object
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"local.packages/ast"
+)
+
+// Save 環境に束縛されている値を、Monkeyのlet文の並びとしてwに書き出す。定数はconst文として書き出す。
+// 書き出した内容を読み戻すには構文解析と評価が必要なため、読み戻す処理は評価器とともに追加する。
+// 保存するのは整数・浮動小数点数・文字列・真偽値・null・配列・ハッシュ・関数で、外側のスコープは含めない。
+// 組み込み関数などのそれ以外の値と、それを要素に含む配列やハッシュは保存しない。
+// ハッシュは格納された順に書き出す。関数は整形したソースとして書き出す。
+func (e *Environment) Save(w io.Writer) error {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		source, ok := sourceOf(e.store[name])
+		if !ok {
+			continue
+		}
+		keyword := "let"
+		if e.constants[name] {
+			keyword = "const"
+		}
+		if _, err := fmt.Fprintf(bw, "%s %s = %s;\n", keyword, name, source); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// 文字列リテラルの中でエスケープが必要な文字の置き換え
+var stringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// 値をMonkeyのソースとして書いた文字列を返す。保存できない値の場合はfalseを返す。
+func sourceOf(obj Object) (string, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return strconv.FormatInt(obj.Value, 10), true
+	case *Float:
+		// 無限大とNaNはリテラルとして書けない。
+		if math.IsInf(obj.Value, 0) || math.IsNaN(obj.Value) {
+			return "", false
+		}
+		return obj.Inspect(), true
+	case *String:
+		return `"` + stringEscaper.Replace(obj.Value) + `"`, true
+	case *Boolean:
+		return strconv.FormatBool(obj.Value), true
+	case *Null:
+		return "null", true
+	case *Array:
+		elements := []string{}
+		for _, el := range obj.Elements {
+			s, ok := sourceOf(el)
+			if !ok {
+				return "", false
+			}
+			elements = append(elements, s)
+		}
+		return "[" + strings.Join(elements, ", ") + "]", true
+	case *Hash:
+		pairs := []string{}
+		for _, pair := range obj.OrderedPairs() {
+			key, ok := sourceOf(pair.Key)
+			if !ok {
+				return "", false
+			}
+			value, ok := sourceOf(pair.Value)
+			if !ok {
+				return "", false
+			}
+			pairs = append(pairs, key+": "+value)
+		}
+		return "{" + strings.Join(pairs, ", ") + "}", true
+	case *Function:
+		lit := &ast.FunctionLiteral{
+			Name:       obj.Name,
+			Parameters: obj.Parameters,
+			Defaults:   obj.Defaults,
+			Rest:       obj.Rest,
+			Body:       obj.Body,
+		}
+		program := &ast.Program{Statements: []ast.Statement{&ast.ExpressionStatement{Expression: lit}}}
+		return strings.TrimRight(program.Format(), ";\n"), true
+	default:
+		return "", false
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"local.packages/ast"
 	"local.packages/lexer"
@@ -10,40 +11,130 @@ import (
 )
 
 // Parser 構文解析器
+// 1つのParserを複数のゴルーチンから同時に使ってはならないが、
+// Newで別々に生成したParserはパッケージ変数を書き換えないため、並行して使える。
 type Parser struct {
 	l *lexer.Lexer
 
-	errors []string
+	errors []ParseError
+	// 同期（synchronize）によって回復済みのエラーの数
+	recoveredErrors int
 
 	curToken  token.Token
 	peekToken token.Token
+	// 現在のトークンと次のトークンの、最後の文字の次の位置
+	curEnd  ast.Position
+	peekEnd ast.Position
 
-	prefixParseFns map[token.TokenType]prefixParseFn
-	infixParseFns  map[token.TokenType]infixParseFn
+	prefixParseFns map[token.TokenType]PrefixParseFn
+	infixParseFns  map[token.TokenType]InfixParseFn
+	// 中置演算子の優先順位。RegisterInfixOperatorで追加できるよう、Parserごとに持つ。
+	precedences map[token.TokenType]int
 }
 
 const (
 	_ int = iota
 	LOWEST
+	OR          // ||
+	AND         // &&
 	EQUALS      // =
 	LESSGREATER // >, <
+	BIT_OR      // |
+	BIT_XOR     // ^
+	BIT_AND     // &
 	SUM         // +
+	SHIFT       // <<, >>
 	PRODUCT     // *
 	PREFIX      // -X, !X
 	CALL        // myFunction(X
+	INDEX       // array[index]
 )
 
+// 複合代入演算子のトークンと、展開後の中置演算子のトークンのマッピング
+// すべてのParserで共有するため、読み取りだけを行う。
+var compoundAssignOperators = map[token.TokenType]token.TokenType{
+	token.PLUS_EQ:     token.PLUS,
+	token.MINUS_EQ:    token.MINUS,
+	token.ASTERISK_EQ: token.ASTERISK,
+	token.SLASH_EQ:    token.SLASH,
+}
+
+// 中置演算子のトークンと優先順位の既定のマッピング
+// すべてのParserで共有するため、読み取りだけを行う。変更はParserごとの複製に対して行う。
+var precedences = map[token.TokenType]int{
+	token.OR:        OR,
+	token.AND:       AND,
+	token.EQ:        EQUALS,
+	token.NOT_EQ:    EQUALS,
+	token.LT:        LESSGREATER,
+	token.GT:        LESSGREATER,
+	token.PIPE:      BIT_OR,
+	token.CARET:     BIT_XOR,
+	token.AMPERSAND: BIT_AND,
+	token.PLUS:      SUM,
+	token.MINUS:     SUM,
+	token.LSHIFT:    SHIFT,
+	token.RSHIFT:    SHIFT,
+	token.SLASH:     PRODUCT,
+	token.ASTERISK:  PRODUCT,
+	token.PERCENT:   PRODUCT,
+	token.LPAREN:    CALL,
+	token.LBRACKET:  INDEX,
+}
+
 // New Parserを生成する。
 // Lexerを受け取り、トークンを読み込むことでParserが初期化される。
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:      l,
-		errors: []string{},
+		errors: []ParseError{},
 	}
 
-	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
+	p.prefixParseFns = make(map[token.TokenType]PrefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.BACKTICK, p.parseTemplateLiteral)
+	p.registerPrefix(token.TRUE, p.parseBoolean)
+	p.registerPrefix(token.FALSE, p.parseBoolean)
+	p.registerPrefix(token.NULL, p.parseNull)
+	p.registerPrefix(token.BANG, p.parsePrefixExpression)
+	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
+	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
+	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
+	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.SWITCH, p.parseSwitchExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+
+	p.infixParseFns = make(map[token.TokenType]InfixParseFn)
+	p.registerInfix(token.PLUS, p.parseInfixExpression)
+	p.registerInfix(token.MINUS, p.parseInfixExpression)
+	p.registerInfix(token.SLASH, p.parseInfixExpression)
+	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.EQ, p.parseInfixExpression)
+	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.LT, p.parseInfixExpression)
+	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.LSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.RSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+
+	p.precedences = make(map[token.TokenType]int, len(precedences))
+	for t, precedence := range precedences {
+		p.precedences[t] = precedence
+	}
 
 	// トークンを2つ読み込む。curTokenとpeekTokenがセットされる。
 	p.nextToken()
@@ -55,7 +146,11 @@ func New(l *lexer.Lexer) *Parser {
 // 次のトークンを読み込む。
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
+	p.curEnd = p.peekEnd
 	p.peekToken = p.l.NextToken()
+
+	line, column := p.l.Position()
+	p.peekEnd = ast.Position{Line: line, Column: column}
 }
 
 // ParseProgram ファイル末尾に達するまでStatementを読み込み、読み込んだStatementを持つProgramを返す。
@@ -65,28 +160,71 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 	for p.curToken.Type != token.EOF {
 		stmt := p.parseStatement()
-		if stmt != nil {
+		if p.recoverFromError() {
+			// 構文エラーを含む文は捨てる。
+		} else if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
 		p.nextToken()
 	}
 
+	// プログラムの範囲は最初の文の始まりから最後の文の終わりまで。
+	if n := len(program.Statements); n > 0 {
+		start, _ := program.Statements[0].Pos()
+		_, end := program.Statements[n-1].Pos()
+		program.SetPos(start, end)
+	}
+
 	return program
 }
 
 // Statementを構築して返す。
+// 構文エラーが無ければ、文のソース上の範囲を記録する。
 func (p *Parser) parseStatement() ast.Statement {
+	start := p.curToken
+	numErrors := len(p.errors)
+
+	stmt := p.parseStatementByToken()
+	if len(p.errors) == numErrors {
+		// エラーが無ければ、stmtはnilではない。
+		p.setPos(stmt, start)
+	}
+
+	return stmt
+}
+
+// 現在のトークンの種別に応じてStatementを構築して返す。
+func (p *Parser) parseStatementByToken() ast.Statement {
 	switch p.curToken.Type {
 	case token.LET:
+		if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+			return p.parseDestructuringLetStatement()
+		}
 		return p.parseLetStatement()
+	case token.CONST:
+		return p.parseConstStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.IDENT:
+		if _, ok := compoundAssignOperators[p.peekToken.Type]; ok || p.peekTokenIs(token.ASSIGN) {
+			return p.parseAssignStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
 // LetStatementを構築して返す。
+// "let x;"のように値を持たない宣言の場合、Valueはnilになる。
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
@@ -94,13 +232,136 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 		return nil
 	}
 
-	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	stmt.Name = p.newIdentifier()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		// 値なしの宣言の場合
+		p.nextToken()
+		return stmt
+	}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// ConstStatementを構築して返す。
+// 定数は後から値を代入できないため、let文と異なり値を省略できない。
+func (p *Parser) parseConstStatement() *ast.ConstStatement {
+	stmt := &ast.ConstStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = p.newIdentifier()
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// DestructuringLetStatementを構築して返す。
+// パターンには識別子をカンマで区切って並べ、末尾のカンマを許容する。
+// 空のパターンと、同じ名前を2回以上含むパターンはエラーとする。
+func (p *Parser) parseDestructuringLetStatement() *ast.DestructuringLetStatement {
+	stmt := &ast.DestructuringLetStatement{Token: p.curToken}
+
+	p.nextToken()
+	stmt.Pattern = p.curToken
+
+	end := token.TokenType(token.RBRACKET)
+	if stmt.IsHash() {
+		end = token.RBRACE
+	}
+
+	seen := make(map[string]bool)
+	for !p.peekTokenIs(end) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		name := p.newIdentifier()
+		if seen[name.Value] {
+			p.appendError(name.Token, fmt.Sprintf("duplicate name %s in destructuring pattern", name.Value))
+			return nil
+		}
+		seen[name.Value] = true
+		stmt.Names = append(stmt.Names, name)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+
+	if len(stmt.Names) == 0 {
+		p.appendError(stmt.Pattern, "empty destructuring pattern")
+		return nil
+	}
 
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// AssignStatementを構築して返す。
+// "x += 1"のような複合代入は"x = x + 1"に展開する。
+func (p *Parser) parseAssignStatement() *ast.AssignStatement {
+	stmt := &ast.AssignStatement{Name: p.newIdentifier()}
+
+	p.nextToken()
+	stmt.Token = p.curToken
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if operator, ok := compoundAssignOperators[stmt.Token.Type]; ok {
+		stmt.Value = &ast.InfixExpression{
+			Token: token.Token{
+				Type:    operator,
+				Literal: string(operator),
+				Line:    stmt.Token.Line,
+				Column:  stmt.Token.Column,
+			},
+			Left:     stmt.Name,
+			Operator: string(operator),
+			Right:    stmt.Value,
+		}
+		p.setPos(stmt.Value, stmt.Name.Token)
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -108,18 +369,154 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 }
 
 // ReturnStatementを構築して返す。
+// "return a, b;"のように複数の値をカンマで区切って並べた場合は、TupleLiteralを返す値とする。
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
+	// 値を持たない"return;"。ブロックの末尾や入力の末尾ではセミコロンを省略できる。
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		return stmt
+	}
+	if p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) {
+		return stmt
+	}
+
+	p.nextToken()
+	start := p.curToken
+	stmt.ReturnValue = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.COMMA) {
+		tuple := &ast.TupleLiteral{Token: p.peekToken, Elements: []ast.Expression{stmt.ReturnValue}}
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			p.nextToken()
+			tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+		}
+		p.setPos(tuple, start)
+		stmt.ReturnValue = tuple
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// WhileStatementを構築して返す。
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
+// ForStatementを構築して返す。
+// 初期化文・条件式・後続の文はいずれも省略できる。
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	// 初期化文
 	p.nextToken()
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Init = p.parseStatement()
+		if !p.curTokenIs(token.SEMICOLON) && !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+
+	// 条件式
+	p.nextToken()
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Condition = p.parseExpression(LOWEST)
+		if !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+
+	// 後続の文
+	p.nextToken()
+	if !p.curTokenIs(token.RPAREN) {
+		stmt.Post = p.parseStatement()
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
+// BreakStatementを構築して返す。
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// ContinueStatementを構築して返す。
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
 	return stmt
 }
 
+// "{"から"}"までのブロック文を解析して返す。
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.curToken}
+	block.Statements = []ast.Statement{}
+
+	p.nextToken()
+
+	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		stmt := p.parseStatement()
+		if p.recoverFromError() {
+			// 構文エラーを含む文は捨てる。
+		} else if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+		p.nextToken()
+	}
+
+	p.setPos(block, block.Token)
+
+	return block
+}
+
 // 式文を解析する。
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
@@ -134,82 +531,933 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 }
 
 // 式を解析して返す。
-// 前置に関連付けられた構文解析関数を呼び出し、その結果を返す。
+// 前置に関連付けられた構文解析関数を呼び出し、
+// 次のトークンの優先順位がprecedenceより高い間は中置に関連付けられた構文解析関数で式を結合する。
+// 式を終えられるトークンで行が終わる場合は、セミコロンと同じく式の終わりとする。
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
+		p.noPrefixParseFnError(p.curToken)
 		return nil
 	}
+	start := p.curToken
 	leftEx := prefix()
+	p.setPos(leftEx, start)
+
+	for !p.peekTokenIs(token.SEMICOLON) && !p.endsStatementAtNewline() && precedence < p.peekPrecedence() {
+		infix := p.infixParseFns[p.peekToken.Type]
+		if infix == nil {
+			return leftEx
+		}
+
+		p.nextToken()
+
+		// 中置式の範囲は左辺の始まりから右辺の終わりまで。
+		leftEx = infix(leftEx)
+		p.setPos(leftEx, start)
+	}
+
 	return leftEx
 }
 
-// 識別子を解析して返す。
-func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+// 現在のトークンから識別子を生成し、ソース上の範囲を記録して返す。
+func (p *Parser) newIdentifier() *ast.Identifier {
+	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	p.setPos(ident, p.curToken)
+	return ident
 }
 
-// 整数リテラルを解析して返す。
-func (p *Parser) parseIntegerLiteral() ast.Expression {
-	lit := &ast.IntegerLiteral{Token: p.curToken}
+// ノードにソース上の範囲を記録する。
+// 範囲はstartの始まりから現在のトークンの終わりまでとする。
+func (p *Parser) setPos(node ast.Node, start token.Token) {
+	n, ok := node.(interface{ SetPos(start, end ast.Position) })
+	if !ok {
+		return
+	}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
-	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
-		return nil
+	n.SetPos(ast.Position{Line: start.Line, Column: start.Column}, p.curEnd)
+}
+
+// 前置式を解析して返す。
+// 演算子の右側はPREFIXの優先順位で解析するため、"-a * b"は"((-a) * b)"になる。
+func (p *Parser) parsePrefixExpression() ast.Expression {
+	expression := &ast.PrefixExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Literal,
 	}
 
-	lit.Value = value
+	p.nextToken()
 
-	return lit
+	expression.Right = p.parseExpression(PREFIX)
+
+	return expression
 }
 
-// 現在のトークンがtと等しい時にtrueを返す。
-func (p *Parser) curTokenIs(t token.TokenType) bool {
-	return p.curToken.Type == t
+// 中置式を解析して返す。
+// leftは演算子の左辺で、右辺は演算子の優先順位で解析する。
+func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
+	expression := &ast.InfixExpression{
+		Token:    p.curToken,
+		Operator: p.curToken.Literal,
+		Left:     left,
+	}
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+	expression.Right = p.parseExpression(precedence)
+
+	return expression
 }
 
-// 次のトークンがtと等しい時にtrueを返す。
-func (p *Parser) peekTokenIs(t token.TokenType) bool {
-	return p.peekToken.Type == t
+// 括弧で囲まれた式を解析して返す。
+// 括弧はASTに残さず、囲まれた式の範囲が括弧を含むようにする。
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	p.nextToken()
+
+	exp := p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return exp
 }
 
-// 次のトークンがtと等しい時に次のトークンを読み込み、trueを返す。
-func (p *Parser) expectPeek(t token.TokenType) bool {
-	if p.peekTokenIs(t) {
+// if式を解析して返す。
+// "else if"は、入れ子のif式を唯一の文として持つブロックをAlternativeとする。
+func (p *Parser) parseIfExpression() ast.Expression {
+	expression := &ast.IfExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Consequence = p.parseBlockStatement()
+
+	if !p.peekTokenIs(token.ELSE) {
+		return expression
+	}
+	p.nextToken()
+
+	if p.peekTokenIs(token.IF) {
 		p.nextToken()
-		return true
-	} else {
-		p.peekError(t)
-		return false
+		expression.Alternative = p.parseElseIf()
+		if expression.Alternative == nil {
+			return nil
+		}
+		return expression
 	}
-}
 
-// 前置構文を登録する。
-func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
-	p.prefixParseFns[tokenType] = fn
-}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
 
-// 中置構文を登録する。
-func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
-	p.infixParseFns[tokenType] = fn
+	expression.Alternative = p.parseBlockStatement()
+
+	return expression
 }
 
-type (
-	// 前置構文解析関数
-	prefixParseFn func() ast.Expression
-	// 中置構文解析関数
-	infixParseFn func(expression ast.Expression) ast.Expression
-)
+// "else if"の"if"以降を解析し、入れ子のif式を唯一の文として持つブロックを返す。
+func (p *Parser) parseElseIf() *ast.BlockStatement {
+	start := p.curToken
+	block := &ast.BlockStatement{Token: start}
 
-// Errors エラーの文字列のスライスを返す。
-func (p *Parser) Errors() []string {
-	return p.errors
+	elseIf := p.parseIfExpression()
+	if elseIf == nil {
+		return nil
+	}
+	p.setPos(elseIf, start)
+
+	stmt := &ast.ExpressionStatement{Token: start, Expression: elseIf}
+	p.setPos(stmt, start)
+	block.Statements = []ast.Statement{stmt}
+	p.setPos(block, start)
+
+	return block
 }
 
-// peekTokenが期待されたものでない場合にエラーのスライスに追加する。
-func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+// switch式を解析して返す。
+// caseとdefaultは"case 1, 2:"や"default:"の後に文を並べ、次のcase、default、'}'までを本体とする。
+func (p *Parser) parseSwitchExpression() ast.Expression {
+	expression := &ast.SwitchExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Cases = []*ast.SwitchCase{}
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		switch p.curToken.Type {
+		case token.CASE:
+			c := p.parseSwitchCase()
+			if c == nil {
+				return nil
+			}
+			expression.Cases = append(expression.Cases, c)
+		case token.DEFAULT:
+			if expression.Default != nil {
+				p.appendError(p.curToken, "multiple defaults in switch")
+				return nil
+			}
+			if !p.expectPeek(token.COLON) {
+				return nil
+			}
+			expression.Default = p.parseSwitchCaseBody()
+		default:
+			msg := fmt.Sprintf("expected case or default in switch, got %s instead", p.curToken.Type)
+			p.appendError(p.curToken, msg)
+			return nil
+		}
+	}
+	p.nextToken()
+
+	return expression
+}
+
+// switch式のcaseを解析して返す。
+func (p *Parser) parseSwitchCase() *ast.SwitchCase {
+	c := &ast.SwitchCase{Token: p.curToken}
+
+	p.nextToken()
+	c.Values = []ast.Expression{p.parseExpression(LOWEST)}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		c.Values = append(c.Values, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+
+	c.Body = p.parseSwitchCaseBody()
+
+	return c
+}
+
+// caseとdefaultの本体を解析して返す。
+// 現在のトークンが':'の状態で呼び出し、本体の最後のトークンで終える。
+func (p *Parser) parseSwitchCaseBody() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.curToken}
+	block.Statements = []ast.Statement{}
+
+	for !p.peekTokenIs(token.CASE) && !p.peekTokenIs(token.DEFAULT) && !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.EOF) {
+		p.nextToken()
+		stmt := p.parseStatement()
+		if p.recoverFromError() {
+			// 構文エラーを含む文は捨てる。
+		} else if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+	}
+
+	p.setPos(block, block.Token)
+
+	return block
+}
+
+// try式を解析して返す。
+// "try { ... } catch (e) { ... }"の形で、catchとエラーを束縛する識別子は省略できない。
+func (p *Parser) parseTryExpression() ast.Expression {
+	expression := &ast.TryExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Body = p.parseBlockStatement()
+
+	if !p.expectPeek(token.CATCH) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	expression.CatchParam = p.newIdentifier()
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Handler = p.parseBlockStatement()
+
+	return expression
+}
+
+// 識別子を解析して返す。
+func (p *Parser) parseIdentifier() ast.Expression {
+	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+}
+
+// 整数リテラルを解析して返す。
+func (p *Parser) parseIntegerLiteral() ast.Expression {
+	lit := &ast.IntegerLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
+		p.appendError(p.curToken, msg)
+		return nil
+	}
+
+	lit.Value = value
+
+	return lit
+}
+
+// 浮動小数点数リテラルを解析して返す。
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.appendError(p.curToken, msg)
+		return nil
+	}
+
+	lit.Value = value
+
+	return lit
+}
+
+// 文字列リテラルを解析して返す。
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+}
+
+// テンプレート文字列リテラルを解析して返す。
+// 地の文と"${"から'}'までの埋め込み式が交互に並び、隣り合う地の文は1つにまとめる。
+func (p *Parser) parseTemplateLiteral() ast.Expression {
+	lit := &ast.TemplateLiteral{Token: p.curToken}
+
+	var text strings.Builder
+	for !p.peekTokenIs(token.BACKTICK) {
+		p.nextToken()
+
+		switch p.curToken.Type {
+		case token.TEMPLATE_STRING:
+			text.WriteString(p.curToken.Literal)
+		case token.DOLLAR_LBRACE:
+			p.nextToken()
+			exp := p.parseExpression(LOWEST)
+			if !p.expectPeek(token.RBRACE) {
+				return nil
+			}
+			lit.Strings = append(lit.Strings, text.String())
+			lit.Expressions = append(lit.Expressions, exp)
+			text.Reset()
+		default:
+			p.appendError(lit.Token, "unterminated template literal")
+			return nil
+		}
+	}
+	p.nextToken()
+
+	lit.Strings = append(lit.Strings, text.String())
+
+	return lit
+}
+
+// 真偽値リテラルを解析して返す。
+func (p *Parser) parseBoolean() ast.Expression {
+	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
+}
+
+// nullリテラルを解析して返す。
+func (p *Parser) parseNull() ast.Expression {
+	return &ast.NullLiteral{Token: p.curToken}
+}
+
+// マクロリテラルを解析して返す。
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	lit := &ast.MacroLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	paramsToken := p.curToken
+	params, defaults, rest := p.parseFunctionParameters()
+	if len(defaults) != 0 {
+		p.appendError(paramsToken, "default parameter values are not allowed in macro")
+		return nil
+	}
+	if rest != nil {
+		p.appendError(paramsToken, "rest parameter is not allowed in macro")
+		return nil
+	}
+	lit.Parameters = params
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	lit.Body = p.parseBlockStatement()
+
+	return lit
+}
+
+// 関数リテラルを解析して返す。
+// "fn fact(n) { ... }"のように、'fn'と'('の間に関数の名前を置ける。
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	lit := &ast.FunctionLiteral{Token: p.curToken}
+
+	if p.peekTokenIs(token.IDENT) {
+		p.nextToken()
+		lit.Name = p.newIdentifier()
+	}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	lit.Parameters, lit.Defaults, lit.Rest = p.parseFunctionParameters()
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	lit.Body = p.parseBlockStatement()
+
+	return lit
+}
+
+// 括弧で囲まれたカンマ区切りの仮引数の並びを解析する。
+// 現在のトークンが '(' の状態で呼び出し、')' で終える。
+// "y = 10"のようにデフォルト値を持つ仮引数は、名前とデフォルト値の式の組をdefaultsに入れる。
+// デフォルト値を持つ仮引数の後には、デフォルト値を持たない仮引数を置けない。
+// "...rest"のような残りの引数を受け取る仮引数はrestとして返し、最後にだけ置ける。
+// 閉じる直前の末尾のカンマを許容する。
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, map[string]ast.Expression, *ast.Identifier) {
+	identifiers := []*ast.Identifier{}
+	var defaults map[string]ast.Expression
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return identifiers, defaults, nil
+	}
+
+	for {
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			return p.parseRestParameter(identifiers, defaults)
+		}
+
+		if !p.expectPeek(token.IDENT) {
+			return nil, nil, nil
+		}
+		ident := p.newIdentifier()
+		identifiers = append(identifiers, ident)
+
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			if defaults == nil {
+				defaults = make(map[string]ast.Expression)
+			}
+			defaults[ident.Value] = p.parseExpression(LOWEST)
+		} else if len(defaults) != 0 {
+			msg := fmt.Sprintf("parameter %s without default value follows parameter with default value", ident.Value)
+			p.appendError(ident.Token, msg)
+			return nil, nil, nil
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+		if p.peekTokenIs(token.RPAREN) {
+			// 末尾のカンマは仮引数を生まない。
+			break
+		}
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil, nil, nil
+	}
+
+	return identifiers, defaults, nil
+}
+
+// 残りの引数を受け取る仮引数を解析し、それまでに解析した仮引数とあわせて返す。
+// 現在のトークンが"..."の状態で呼び出し、')'で終える。
+func (p *Parser) parseRestParameter(identifiers []*ast.Identifier, defaults map[string]ast.Expression) ([]*ast.Identifier, map[string]ast.Expression, *ast.Identifier) {
+	if !p.expectPeek(token.IDENT) {
+		return nil, nil, nil
+	}
+	rest := p.newIdentifier()
+
+	if p.peekTokenIs(token.COMMA) {
+		msg := fmt.Sprintf("rest parameter %s must be the last parameter", rest.Value)
+		p.appendError(rest.Token, msg)
+		return nil, nil, nil
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil, nil, nil
+	}
+
+	return identifiers, defaults, rest
+}
+
+// 関数呼び出し式を解析して返す。
+// functionは呼び出される関数を表す式で、識別子と関数リテラルのどちらにも使う。
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	exp := &ast.CallExpression{Token: p.curToken, Function: function}
+	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	if exp.Arguments == nil {
+		return nil
+	}
+
+	return exp
+}
+
+// 配列リテラルを解析して返す。
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.curToken}
+
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+
+	return array
+}
+
+// endで閉じられるまでのカンマ区切りの式を解析して返す。
+// 空のリストと、閉じる直前の末尾のカンマを許容する。
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
+
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return list
+	}
+
+	p.nextToken()
+	list = append(list, p.parseListElement(end))
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.peekTokenIs(end) {
+			// 末尾のカンマは要素を生まない。
+			break
+		}
+		p.nextToken()
+		list = append(list, p.parseListElement(end))
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+
+	return list
+}
+
+// リストの要素を1つ解析して返す。
+// ']'で閉じる配列リテラルの要素に限り、"...xs"のスプレッド要素を許す。
+func (p *Parser) parseListElement(end token.TokenType) ast.Expression {
+	if end == token.RBRACKET && p.curTokenIs(token.ELLIPSIS) {
+		return p.parseSpreadExpression()
+	}
+
+	return p.parseExpression(LOWEST)
+}
+
+// 現在のトークンが'...'の状態で呼び出し、展開するコレクションの式を解析してSpreadExpressionを返す。
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	spread := &ast.SpreadExpression{Token: p.curToken}
+
+	p.nextToken()
+	spread.Value = p.parseExpression(LOWEST)
+	p.setPos(spread, spread.Token)
+
+	return spread
+}
+
+// 添字式を解析して返す。
+// leftは添字でアクセスされる式で、配列とハッシュのどちらにも使う。
+// 添字の位置に':'があればスライス式として解析する。
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	tok := p.curToken
+
+	var index ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		index = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		return p.parseSliceExpression(tok, left, index)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return &ast.IndexExpression{Token: tok, Left: left, Index: index}
+}
+
+// スライス式の':'以降を解析して返す。
+// 現在のトークンが':'の状態で呼び出す。startは解析済みの開始位置で、省略された場合はnil。
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return exp
+}
+
+// ハッシュリテラルを解析して返す。
+// リテラルのキーが重複している場合はエラーとする。
+// "...h"のスプレッド要素は、値をnilとしてPairsに入れる。
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken}
+	hash.Pairs = make(map[ast.Expression]ast.Expression)
+
+	// 重複を検出するために、リテラルのキーを覚えておく。
+	keys := make(map[string]bool)
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		if p.curTokenIs(token.ELLIPSIS) {
+			hash.Pairs[p.parseSpreadExpression()] = nil
+
+			if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+				return nil
+			}
+			continue
+		}
+
+		keyToken := p.curToken
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		if k, ok := hashLiteralKey(key); ok {
+			if keys[k] {
+				msg := fmt.Sprintf("duplicate key %s in hash literal", key.String())
+				p.appendError(keyToken, msg)
+				return nil
+			}
+			keys[k] = true
+		}
+
+		hash.Pairs[key] = value
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return hash
+}
+
+// ハッシュリテラルのキーの重複を判定するための値を返す。
+// リテラル以外のキーは評価するまで値が決まらないため、falseを返す。
+func hashLiteralKey(key ast.Expression) (string, bool) {
+	switch key := key.(type) {
+	case *ast.IntegerLiteral:
+		return fmt.Sprintf("%s:%d", token.INT, key.Value), true
+	case *ast.StringLiteral:
+		return fmt.Sprintf("%s:%s", token.STRING, key.Value), true
+	default:
+		return "", false
+	}
+}
+
+// 直前の文の解析で新たな構文エラーが発生していれば、次の文の始まりまで同期してtrueを返す。
+// ネストしたブロックの中で回復済みのエラーに対しては、外側の文で再び同期しない。
+func (p *Parser) recoverFromError() bool {
+	if len(p.errors) == p.recoveredErrors {
+		return false
+	}
+
+	p.synchronize()
+	p.recoveredErrors = len(p.errors)
+
+	return true
+}
+
+// 同期トークンまでトークンを読み飛ばす。
+// 現在のトークンがセミコロンになるか、次のトークンが"}"になるか、ファイル末尾に達するまで読み進める。
+// これにより、1つの構文エラーから連鎖する無関係なエラーを報告せずに済む。
+func (p *Parser) synchronize() {
+	for !p.curTokenIs(token.SEMICOLON) && !p.peekTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		p.nextToken()
+	}
+}
+
+// 現在のトークンがtと等しい時にtrueを返す。
+func (p *Parser) curTokenIs(t token.TokenType) bool {
+	return p.curToken.Type == t
+}
+
+// 次のトークンがtと等しい時にtrueを返す。
+func (p *Parser) peekTokenIs(t token.TokenType) bool {
+	return p.peekToken.Type == t
+}
+
+// 行末で文を終えられるトークン
+// 識別子・リテラルや閉じ括弧のように式を終えられるトークンで行が終わる場合、改行を文の区切りとする。
+// 演算子やカンマ、開き括弧で行が終わる場合は、次の行に式が続くものとする。
+var statementEndTokens = map[token.TokenType]bool{
+	token.IDENT:    true,
+	token.INT:      true,
+	token.FLOAT:    true,
+	token.STRING:   true,
+	token.BACKTICK: true,
+	token.TRUE:     true,
+	token.FALSE:    true,
+	token.NULL:     true,
+	token.RPAREN:   true,
+	token.RBRACKET: true,
+	token.RBRACE:   true,
+}
+
+// 現在のトークンで行が終わり、改行を文の区切りとみなす場合にtrueを返す。
+// "a\n(b)"や"a\n-b"を関数呼び出しや減算としてつなげず、2つの文として解析するために使う。
+// "1 +\n2"のように演算子で行が終わる場合は、次の行に式が続く。
+func (p *Parser) endsStatementAtNewline() bool {
+	return statementEndTokens[p.curToken.Type] && p.peekToken.Line > p.curEnd.Line
+}
+
+// 次のトークンの優先順位を返す。
+func (p *Parser) peekPrecedence() int {
+	if p, ok := p.precedences[p.peekToken.Type]; ok {
+		return p
+	}
+	return LOWEST
+}
+
+// 現在のトークンの優先順位を返す。
+func (p *Parser) curPrecedence() int {
+	if p, ok := p.precedences[p.curToken.Type]; ok {
+		return p
+	}
+	return LOWEST
+}
+
+// 次のトークンがtと等しい時に次のトークンを読み込み、trueを返す。
+func (p *Parser) expectPeek(t token.TokenType) bool {
+	if p.peekTokenIs(t) {
+		p.nextToken()
+		return true
+	} else {
+		p.peekError(t)
+		return false
+	}
+}
+
+// 前置構文を登録する。
+func (p *Parser) registerPrefix(tokenType token.TokenType, fn PrefixParseFn) {
+	p.prefixParseFns[tokenType] = fn
+}
+
+// 中置構文を登録する。
+func (p *Parser) registerInfix(tokenType token.TokenType, fn InfixParseFn) {
+	p.infixParseFns[tokenType] = fn
+}
+
+// RegisterInfixOperator 中置演算子を優先順位とともに登録する。
+// 既に登録されている演算子の場合は、優先順位と構文解析関数を置き換える。
+// fnがnilの場合は、左辺と右辺を持つ通常の中置式(ast.InfixExpression)として解析する。
+func (p *Parser) RegisterInfixOperator(t token.TokenType, precedence int, fn InfixParseFn) {
+	if fn == nil {
+		fn = p.parseInfixExpression
+	}
+
+	p.precedences[t] = precedence
+	p.registerInfix(t, fn)
+}
+
+type (
+	// PrefixParseFn 前置構文解析関数
+	PrefixParseFn func() ast.Expression
+	// InfixParseFn 中置構文解析関数
+	// 現在のトークンが演算子の状態で、解析済みの左辺を受け取って呼び出される。
+	InfixParseFn func(expression ast.Expression) ast.Expression
+)
+
+// ParseError 位置情報を持つ構文エラー
+type ParseError struct {
+	Message string
+	// エラーの原因となったトークンの行番号
+	Line int
+	// エラーの原因となったトークンの列番号
+	Column int
+}
+
+// Error "line:column: message"の形式のエラーメッセージを返す。
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// FormatError エラーのメッセージに続けて、ソースのエラーの行と、エラーの列を指すキャレットを並べた文字列を返す。
+//
+//	1:9: no prefix parse function for ; found
+//	  let x = ;
+//	          ^
+//
+// キャレットの前はソースの行と同じ位置にタブを置き、タブを含む行でもキャレットの位置がずれないようにする。
+// エラーの行がソースに無い場合は、メッセージだけを返す。
+func FormatError(src string, err ParseError) string {
+	lines := strings.Split(src, "\n")
+	if err.Line < 1 || err.Line > len(lines) {
+		return err.Error()
+	}
+	line := strings.TrimSuffix(lines[err.Line-1], "\r")
+
+	// 列番号は文字（ルーン）単位で数える。
+	var padding strings.Builder
+	column := 1
+	for _, r := range line {
+		if column >= err.Column {
+			break
+		}
+		if r == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+		column++
+	}
+	for ; column < err.Column; column++ {
+		padding.WriteRune(' ')
+	}
+
+	return err.Error() + "\n  " + line + "\n  " + padding.String() + "^"
+}
+
+// Errors エラーの文字列のスライスを返す。
+// 各エラーの文字列には"line:column"の形式で位置情報が付与される。
+func (p *Parser) Errors() []string {
+	msgs := make([]string, len(p.errors))
+	for i := range p.errors {
+		msgs[i] = p.errors[i].Error()
+	}
+	return msgs
+}
+
+// StructuredErrors 位置情報を持つエラーのスライスを返す。
+func (p *Parser) StructuredErrors() []ParseError {
+	return p.errors
+}
+
+// Err 構文エラーをまとめたParseErrorsをerrorとして返す。エラーが無ければnilを返す。
+func (p *Parser) Err() error {
+	if len(p.errors) == 0 {
+		return nil
+	}
+
+	errs := make(ParseErrors, len(p.errors))
+	copy(errs, p.errors)
+	return errs
+}
+
+// ParseErrors 複数の構文エラーをまとめたエラー
+// errors.Asで先頭のエラーを*ParseErrorとして取り出せ、errors.Unwrapで残りのエラーをたどれる。
+// errors.Joinでまとめたエラーはerrors.Unwrapでたどれないため、先頭と残りの連なりとして表す。
+type ParseErrors []ParseError
+
+// Error 各エラーのメッセージを改行で区切って返す。
+func (e ParseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap 先頭を除いた残りのエラーを返す。残りが無ければnilを返す。
+func (e ParseErrors) Unwrap() error {
+	if len(e) <= 1 {
+		return nil
+	}
+	return e[1:]
+}
+
+// As targetが*ParseErrorへのポインタであれば、先頭のエラーを設定してtrueを返す。
+func (e ParseErrors) As(target interface{}) bool {
+	pe, ok := target.(**ParseError)
+	if !ok || len(e) == 0 {
+		return false
+	}
+
+	first := e[0]
+	*pe = &first
+	return true
+}
+
+// トークンtの位置でエラーをエラーのスライスに追加する。
+func (p *Parser) appendError(t token.Token, msg string) {
+	p.errors = append(p.errors, ParseError{Message: msg, Line: t.Line, Column: t.Column})
+}
+
+// peekTokenが期待されたものでない場合にエラーのスライスに追加する。
+func (p *Parser) peekError(t token.TokenType) {
+	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
+	p.appendError(p.peekToken, msg)
+}
+
+// トークンtに対応する前置構文解析関数が無い場合にエラーのスライスに追加する。
+// 閉じられていない文字列リテラルは、字句解析器がILLEGALとして返すため、ここで未終端のエラーとする。
+func (p *Parser) noPrefixParseFnError(t token.Token) {
+	if t.Type == token.ILLEGAL && strings.HasPrefix(t.Literal, `"`) {
+		p.appendError(t, "unterminated string literal")
+		return
+	}
+
+	msg := fmt.Sprintf("no prefix parse function for %s found", t.Type)
+	p.appendError(t, msg)
 }
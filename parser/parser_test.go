@@ -1,10 +1,15 @@
 package parser
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	"local.packages/ast"
 	"local.packages/lexer"
+	"local.packages/token"
 )
 
 func TestLetStatements(t *testing.T) {
@@ -41,6 +46,148 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedHash   bool
+		expectedNames  []string
+		expectedValue  string
+		expectedString string
+	}{
+		{"let [a, b] = [1, 2];", false, []string{"a", "b"}, "[1, 2]", "let [a, b] = [1, 2];"},
+		{"let [head,] = xs", false, []string{"head"}, "xs", "let [head] = xs;"},
+		{"let {x, y} = h;", true, []string{"x", "y"}, "h", "let {x, y} = h;"},
+		{"let {name} = f(1);", true, []string{"name"}, "f(1)", "let {name} = f(1);"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.DestructuringLetStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.DestructuringLetStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.IsHash() != tt.expectedHash {
+			t.Errorf("stmt.IsHash() wrong. want %t, got=%t", tt.expectedHash, stmt.IsHash())
+		}
+
+		if len(stmt.Names) != len(tt.expectedNames) {
+			t.Fatalf("wrong number of names. want %d, got=%d", len(tt.expectedNames), len(stmt.Names))
+		}
+		for i, name := range tt.expectedNames {
+			if stmt.Names[i].Value != name {
+				t.Errorf("name %d wrong. want %s, got=%s", i, name, stmt.Names[i].Value)
+			}
+		}
+
+		if stmt.Value.String() != tt.expectedValue {
+			t.Errorf("stmt.Value wrong. want %q, got=%q", tt.expectedValue, stmt.Value.String())
+		}
+
+		if stmt.String() != tt.expectedString {
+			t.Errorf("stmt.String() wrong. want %q, got=%q", tt.expectedString, stmt.String())
+		}
+	}
+}
+
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedName  string
+		expectedValue string
+	}{
+		{"const x = 1;", "x", "1"},
+		{"const pi = 3", "pi", "3"},
+		{"const f = fn(a) { a * 2 };", "f", "fn(a) { (a * 2) }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ConstStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.ConstStatement. got=%T", program.Statements[0])
+		}
+		if stmt.TokenLiteral() != "const" {
+			t.Errorf("stmt.TokenLiteral not 'const'. got=%q", stmt.TokenLiteral())
+		}
+		if stmt.Name.Value != tt.expectedName {
+			t.Errorf("stmt.Name.Value not %q. got=%q", tt.expectedName, stmt.Name.Value)
+		}
+		if stmt.Value.String() != tt.expectedValue {
+			t.Errorf("stmt.Value wrong. expected=%q, got=%q", tt.expectedValue, stmt.Value.String())
+		}
+	}
+}
+
+func TestConstStatementsError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"const x;", "1:8: expected next token to be =, got ; instead"},
+		{"const = 1;", "1:7: expected next token to be IDENT, got = instead"},
+		{"const [a] = xs;", "1:7: expected next token to be IDENT, got [ instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestDestructuringLetStatementsError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let [] = xs;", "1:5: empty destructuring pattern"},
+		{"let [a, a] = xs;", "1:9: duplicate name a in destructuring pattern"},
+		{"let [a, 1] = xs;", "1:9: expected next token to be IDENT, got INT instead"},
+		{"let [a b] = xs;", "1:8: expected next token to be ], got IDENT instead"},
+		{"let {x} h;", "1:9: expected next token to be =, got IDENT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
 func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	if s.TokenLiteral() != "let" {
 		// トークンがletではない
@@ -67,6 +214,82 @@ func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	return true
 }
 
+func TestAssignStatements(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedIdentifier string
+		expectedValue      string
+	}{
+		{"x = 5;", "x", "5"},
+		{"y = x + 1", "y", "(x + 1)"},
+		{"foobar = y;", "foobar", "y"},
+		{"a += 5;", "a", "(a + 5)"},
+		{"a -= b * 2;", "a", "(a - (b * 2))"},
+		{"a *= 2", "a", "(a * 2)"},
+		{"a /= 2;", "a", "(a / 2)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.AssignStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.AssignStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.Name.Value != tt.expectedIdentifier {
+			t.Errorf("stmt.Name.Value not '%s'. got=%s", tt.expectedIdentifier, stmt.Name.Value)
+		}
+
+		if stmt.Value.String() != tt.expectedValue {
+			t.Errorf("stmt.Value not %q. got=%q", tt.expectedValue, stmt.Value.String())
+		}
+	}
+}
+
+func TestLetStatementValues(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedValue string
+		expected      string
+	}{
+		{"let x = 5;", "5", "let x = 5;"},
+		{"let y = x + 1", "(x + 1)", "let y = (x + 1);"},
+		{"let x;", "", "let x;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.LetStatement. got=%T", program.Statements[0])
+		}
+
+		if s := nodeString(stmt.Value); s != tt.expectedValue {
+			t.Errorf("stmt.Value is not %q. got=%q", tt.expectedValue, s)
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() is not %q. got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	input := `
 	return 5;
@@ -96,6 +319,235 @@ func TestReturnStatements(t *testing.T) {
 	}
 }
 
+func TestReturnStatementValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		isTuple  bool
+	}{
+		{"return 5;", "5", false},
+		{"return a + b;", "(a + b)", false},
+		{"return a, b;", "a, b", true},
+		{"return 1, x * 2, f(y);", "1, (x * 2), f(y)", true},
+		{"return;", "", false},
+		// セミコロンを省略しても、式の後のトークンを読み飛ばさない。
+		{"return 5", "5", false},
+		{"return a + b", "(a + b)", false},
+		{"return a, b", "a, b", true},
+		{"return", "", false},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q: program.Statements does not contain 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ReturnStatement)
+		if !ok {
+			t.Fatalf("%q: stmt not *ast.ReturnStatement. got=%T", tt.input, program.Statements[0])
+		}
+
+		if stmt.ReturnValue == nil {
+			if tt.expected != "" {
+				t.Errorf("%q: stmt.ReturnValue is nil", tt.input)
+			}
+			continue
+		}
+		if stmt.ReturnValue.String() != tt.expected {
+			t.Errorf("%q: stmt.ReturnValue wrong. expected=%q, got=%q", tt.input, tt.expected, stmt.ReturnValue.String())
+		}
+		if _, ok := stmt.ReturnValue.(*ast.TupleLiteral); ok != tt.isTuple {
+			t.Errorf("%q: stmt.ReturnValue is TupleLiteral=%t, want %t", tt.input, ok, tt.isTuple)
+		}
+	}
+}
+
+func TestReturnStatementWithoutSemicolon(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn() { return }", "fn() { return }"},
+		{"fn(a, b) { return a + b }", "fn(a, b) { return (a + b) }"},
+		{"return 1\nreturn 2", "return 1;\nreturn 2;"},
+	}
+
+	for _, tt := range tests {
+		if got := parseProgramString(t, tt.input); got != tt.expected {
+			t.Errorf("%q: program wrong. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := `let i = 0; while (i < 3) { let i = i + 1; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 2, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[1].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not ast.WhileStatement. got=%T", program.Statements[1])
+	}
+
+	if stmt.Condition.String() != "(i < 3)" {
+		t.Errorf("stmt.Condition is not %q. got=%q", "(i < 3)", stmt.Condition.String())
+	}
+
+	if len(stmt.Body.Statements) != 1 {
+		t.Fatalf("stmt.Body.Statements does not contain %d statements. got=%d", 1, len(stmt.Body.Statements))
+	}
+
+	if !testLetStatement(t, stmt.Body.Statements[0], "i") {
+		return
+	}
+}
+
+func TestWhileStatementError(t *testing.T) {
+	tests := []string{
+		"while i < 3 { i; }",
+		"while (i < 3 { i; }",
+		"while (i < 3) i;",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input             string
+		expectedInit      string
+		expectedCondition string
+		expectedPost      string
+	}{
+		{"for (let i = 0; i < 10; i + 1) { i; }", "let i = 0;", "(i < 10)", "(i + 1)"},
+		{"for (i = 0; i < 10; i = i + 1) { i; }", "i = 0;", "(i < 10)", "i = (i + 1);"},
+		{"for (i; i < 10;) { i; }", "i", "(i < 10)", ""},
+		{"for (;;) { i; }", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+		}
+
+		if s := nodeString(stmt.Init); s != tt.expectedInit {
+			t.Errorf("stmt.Init is not %q. got=%q", tt.expectedInit, s)
+		}
+
+		if s := nodeString(stmt.Condition); s != tt.expectedCondition {
+			t.Errorf("stmt.Condition is not %q. got=%q", tt.expectedCondition, s)
+		}
+
+		if s := nodeString(stmt.Post); s != tt.expectedPost {
+			t.Errorf("stmt.Post is not %q. got=%q", tt.expectedPost, s)
+		}
+
+		if len(stmt.Body.Statements) != 1 {
+			t.Errorf("stmt.Body.Statements does not contain %d statements. got=%d", 1, len(stmt.Body.Statements))
+		}
+	}
+}
+
+func TestForStatementError(t *testing.T) {
+	tests := []string{
+		"for let i = 0; i < 10; i { i; }",
+		"for (let i = 0; i < 10) { i; }",
+		"for (;;) i;",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+// 省略可能なノードの文字列表現を返す。nilの場合は空文字列を返す。
+func nodeString(node ast.Node) string {
+	if node == nil {
+		return ""
+	}
+	return node.String()
+}
+
+func TestBreakAndContinueStatements(t *testing.T) {
+	input := `
+	while (a) {
+		while (b) {
+			break;
+		}
+		continue
+	}
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	outer, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T", program.Statements[0])
+	}
+
+	if len(outer.Body.Statements) != 2 {
+		t.Fatalf("outer.Body.Statements does not contain %d statements. got=%d", 2, len(outer.Body.Statements))
+	}
+
+	inner, ok := outer.Body.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("outer.Body.Statements[0] is not ast.WhileStatement. got=%T", outer.Body.Statements[0])
+	}
+
+	if len(inner.Body.Statements) != 1 {
+		t.Fatalf("inner.Body.Statements does not contain %d statements. got=%d", 1, len(inner.Body.Statements))
+	}
+
+	if _, ok := inner.Body.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("inner.Body.Statements[0] is not ast.BreakStatement. got=%T", inner.Body.Statements[0])
+	}
+
+	if _, ok := outer.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Errorf("outer.Body.Statements[1] is not ast.ContinueStatement. got=%T", outer.Body.Statements[1])
+	}
+}
+
 func TestIdentifierExpression(t *testing.T) {
 	input := "foobar;"
 
@@ -154,6 +606,2045 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralRadix(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF;", 255},
+		{"0x1f;", 31},
+		{"0b1010;", 10},
+		{"0B11;", 3},
+		{"1_000;", 1000},
+		{"0xFF_FF;", 65535},
+		{"0x1_0;", 16},
+		{"0b1_0;", 2},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %d. got=%d", tt.expected, literal.Value)
+		}
+	}
+}
+
+func TestIntegerLiteralRadixError(t *testing.T) {
+	tests := []string{
+		"0xG;",
+		"0b102;",
+		"0x_1;",
+		"0xF__F;",
+		"0b1_;",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	input := "3.14;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program has not enough statements. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	literal, ok := stmt.Expression.(*ast.FloatLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value != 3.14 {
+		t.Errorf("literal.Value not %f. got=%f", 3.14, literal.Value)
+	}
+	if literal.TokenLiteral() != "3.14" {
+		t.Errorf("literal.TokenLiteral not %s. got=%s", "3.14", literal.TokenLiteral())
+	}
+}
+
+func TestFloatLiteralDigitSeparator(t *testing.T) {
+	input := "1_000.5;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	literal, ok := stmt.Expression.(*ast.FloatLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value != 1000.5 {
+		t.Errorf("literal.Value not %f. got=%f", 1000.5, literal.Value)
+	}
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"hello\nworld";`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value != "hello\nworld" {
+		t.Errorf("literal.Value not %q. got=%q", "hello\nworld", literal.Value)
+	}
+}
+
+func TestStringLiteralExpressionError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{`"unterminated`, "1:1: unterminated string literal"},
+		{`let s = "abc\`, "1:9: unterminated string literal"},
+		{"puts(\"a\", \"b)", "1:11: unterminated string literal"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestTemplateLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input               string
+		expectedStrings     []string
+		expectedExpressions []string
+		expectedString      string
+	}{
+		{"`a${1+1}b`", []string{"a", "b"}, []string{"(1 + 1)"}, "`a${(1 + 1)}b`"},
+		{"`Hello ${name}!`", []string{"Hello ", "!"}, []string{"name"}, "`Hello ${name}!`"},
+		{"``", []string{""}, []string{}, "``"},
+		{"`plain`", []string{"plain"}, []string{}, "`plain`"},
+		{"`${a}${b}`", []string{"", "", ""}, []string{"a", "b"}, "`${a}${b}`"},
+		{"`\\${a}`", []string{"${a}"}, []string{}, "`${a}`"},
+		{"`x${`y${z}`}`", []string{"x", ""}, []string{"`y${z}`"}, "`x${`y${z}`}`"},
+		{"`${ {\"k\": 1}[\"k\"] }`", []string{"", ""}, []string{"({k:1}[k])"}, "`${({k:1}[k])}`"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		lit, ok := stmt.Expression.(*ast.TemplateLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.TemplateLiteral. got=%T", stmt.Expression)
+		}
+
+		if len(lit.Strings) != len(tt.expectedStrings) {
+			t.Fatalf("input %q: wrong number of strings. want %d, got=%d", tt.input, len(tt.expectedStrings), len(lit.Strings))
+		}
+		for i, s := range tt.expectedStrings {
+			if lit.Strings[i] != s {
+				t.Errorf("input %q: strings[%d] wrong. want %q, got=%q", tt.input, i, s, lit.Strings[i])
+			}
+		}
+
+		if len(lit.Expressions) != len(tt.expectedExpressions) {
+			t.Fatalf("input %q: wrong number of expressions. want %d, got=%d", tt.input, len(tt.expectedExpressions), len(lit.Expressions))
+		}
+		for i, e := range tt.expectedExpressions {
+			if lit.Expressions[i].String() != e {
+				t.Errorf("input %q: expressions[%d] wrong. want %q, got=%q", tt.input, i, e, lit.Expressions[i].String())
+			}
+		}
+
+		if lit.String() != tt.expectedString {
+			t.Errorf("input %q: lit.String() wrong. want %q, got=%q", tt.input, tt.expectedString, lit.String())
+		}
+	}
+}
+
+func TestTemplateLiteralExpressionError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"`abc", "1:1: unterminated template literal"},
+		{"`a${x`", "1:6: expected next token to be }, got ` instead"},
+		{"`a${}`", "1:5: no prefix parse function for } found"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestNullLiteralExpression(t *testing.T) {
+	input := "let x = null; x == null;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	letStmt, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.LetStatement. got=%T", program.Statements[0])
+	}
+	if _, ok := letStmt.Value.(*ast.NullLiteral); !ok {
+		t.Errorf("letStmt.Value not *ast.NullLiteral. got=%T", letStmt.Value)
+	}
+
+	if program.String() != "let x = null;(x == null)" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+}
+
+func TestBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true;", true},
+		{"false;", false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		boolean, ok := stmt.Expression.(*ast.Boolean)
+		if !ok {
+			t.Fatalf("stmt.Expression not *ast.Boolean. got=%T", stmt.Expression)
+		}
+		if boolean.Value != tt.expected {
+			t.Errorf("boolean.Value not %t. got=%t", tt.expected, boolean.Value)
+		}
+	}
+}
+
+func TestSwitchExpression(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedSubject string
+		expectedCases   [][]string // caseごとの値と本体
+		expectedDefault string     // defaultが無ければ空文字列
+		expectedString  string
+	}{
+		{
+			`switch (x) { case 1: "one"; case 2, 3: "two or three"; default: "other"; }`,
+			"x",
+			[][]string{{"1", "one"}, {"2", "3", "two or three"}},
+			"other",
+			"switch (x) { case 1: one case 2, 3: two or three default: other }",
+		},
+		{
+			`switch (name) { case "a": let x = 1; x; case "b": }`,
+			"name",
+			[][]string{{"a", "let x = 1;x"}, {"b", ""}},
+			"",
+			"switch (name) { case a: let x = 1; x case b: }",
+		},
+		{
+			`switch (x > 1) { default: 0 case true: 1 }`,
+			"(x > 1)",
+			[][]string{{"true", "1"}},
+			"0",
+			"switch (x > 1) { case true: 1 default: 0 }",
+		},
+		{"switch (x) {}", "x", [][]string{}, "", "switch (x) {}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.SwitchExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.SwitchExpression. got=%T", stmt.Expression)
+		}
+
+		if exp.Subject.String() != tt.expectedSubject {
+			t.Errorf("exp.Subject wrong. want %q, got=%q", tt.expectedSubject, exp.Subject.String())
+		}
+
+		if len(exp.Cases) != len(tt.expectedCases) {
+			t.Fatalf("wrong number of cases. want %d, got=%d", len(tt.expectedCases), len(exp.Cases))
+		}
+		for i, expected := range tt.expectedCases {
+			c := exp.Cases[i]
+			values := expected[:len(expected)-1]
+			if len(c.Values) != len(values) {
+				t.Errorf("case %d: wrong number of values. want %d, got=%d", i, len(values), len(c.Values))
+				continue
+			}
+			for j, v := range values {
+				if c.Values[j].String() != v {
+					t.Errorf("case %d: value %d wrong. want %q, got=%q", i, j, v, c.Values[j].String())
+				}
+			}
+			if c.Body.String() != expected[len(expected)-1] {
+				t.Errorf("case %d: body wrong. want %q, got=%q", i, expected[len(expected)-1], c.Body.String())
+			}
+		}
+
+		if tt.expectedDefault == "" {
+			if exp.Default != nil {
+				t.Errorf("exp.Default was not nil. got=%q", exp.Default.String())
+			}
+		} else if exp.Default == nil || exp.Default.String() != tt.expectedDefault {
+			t.Errorf("exp.Default wrong. want %q, got=%q", tt.expectedDefault, nodeString(exp.Default))
+		}
+
+		if exp.String() != tt.expectedString {
+			t.Errorf("exp.String() wrong. want %q, got=%q", tt.expectedString, exp.String())
+		}
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedBody    string
+		expectedParam   string
+		expectedHandler string
+		expectedString  string
+	}{
+		{"try { 1 / 0 } catch (e) { 0 }", "(1 / 0)", "e", "0", "try { (1 / 0) } catch (e) { 0 }"},
+		{"try { let x = f(); x } catch (err) { err }", "let x = f();x", "err", "err", "try { let x = f(); x } catch (err) { err }"},
+		{"try {} catch (e) {}", "", "e", "", "try {} catch (e) {}"},
+		{"try {\n  f()\n}\ncatch (e) {\n  g(e)\n}", "f()", "e", "g(e)", "try { f() } catch (e) { g(e) }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.TryExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.TryExpression. got=%T", stmt.Expression)
+		}
+
+		if exp.Body.String() != tt.expectedBody {
+			t.Errorf("exp.Body wrong. want %q, got=%q", tt.expectedBody, exp.Body.String())
+		}
+		if exp.CatchParam.Value != tt.expectedParam {
+			t.Errorf("exp.CatchParam wrong. want %q, got=%q", tt.expectedParam, exp.CatchParam.Value)
+		}
+		if exp.Handler.String() != tt.expectedHandler {
+			t.Errorf("exp.Handler wrong. want %q, got=%q", tt.expectedHandler, exp.Handler.String())
+		}
+		if exp.String() != tt.expectedString {
+			t.Errorf("exp.String() wrong. want %q, got=%q", tt.expectedString, exp.String())
+		}
+	}
+}
+
+func TestTryExpressionError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"try 1 catch (e) {}", "1:5: expected next token to be {, got INT instead"},
+		{"try {}", "1:7: expected next token to be CATCH, got EOF instead"},
+		{"try {} catch {}", "1:14: expected next token to be (, got { instead"},
+		{"try {} catch () {}", "1:15: expected next token to be IDENT, got ) instead"},
+		{"try {} catch (e {}", "1:17: expected next token to be ), got { instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestSwitchExpressionError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"switch x { }", "1:8: expected next token to be (, got IDENT instead"},
+		{"switch (x) { x }", "1:14: expected case or default in switch, got IDENT instead"},
+		{"switch (x) { case 1 x }", "1:21: expected next token to be :, got IDENT instead"},
+		{"switch (x) { default: 1 default: 2 }", "1:25: multiple defaults in switch"},
+		{"switch (x) { case 1: 1", "1:23: expected case or default in switch, got EOF instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `fn(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function literal parameters wrong. want 2, got=%d", len(function.Parameters))
+	}
+	if function.Parameters[0].Value != "x" || function.Parameters[1].Value != "y" {
+		t.Errorf("function literal parameters wrong. got=%s, %s", function.Parameters[0], function.Parameters[1])
+	}
+	if function.Defaults != nil {
+		t.Errorf("function.Defaults is not nil. got=%v", function.Defaults)
+	}
+
+	if len(function.Body.Statements) != 1 {
+		t.Fatalf("function.Body.Statements has not 1 statements. got=%d", len(function.Body.Statements))
+	}
+	if function.Body.String() != "(x + y)" {
+		t.Errorf("function.Body.String() wrong. got=%q", function.Body.String())
+	}
+}
+
+func TestFunctionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedParams   []string
+		expectedDefaults map[string]string
+		expectedString   string
+	}{
+		{"fn() {};", []string{}, nil, "fn() {}"},
+		{"fn(x) {};", []string{"x"}, nil, "fn(x) {}"},
+		{"fn(x, y, z) {};", []string{"x", "y", "z"}, nil, "fn(x, y, z) {}"},
+		{"fn(x, y = 10) { x + y };", []string{"x", "y"}, map[string]string{"y": "10"}, "fn(x, y = 10) { (x + y) }"},
+		{"fn(x = 1, y = x * 2) {};", []string{"x", "y"}, map[string]string{"x": "1", "y": "(x * 2)"}, "fn(x = 1, y = (x * 2)) {}"},
+		{"fn(...rest) { rest };", []string{}, nil, "fn(...rest) { rest }"},
+		{"fn(first, ...rest) { rest };", []string{"first"}, nil, "fn(first, ...rest) { rest }"},
+		{"fn(x, y = 2, ...rest) {};", []string{"x", "y"}, map[string]string{"y": "2"}, "fn(x, y = 2, ...rest) {}"},
+		{"fn(a, b,) {};", []string{"a", "b"}, nil, "fn(a, b) {}"},
+		{"fn(a = 1,) {};", []string{"a"}, map[string]string{"a": "1"}, "fn(a = 1) {}"},
+		{"fn f() {};", []string{}, nil, "fn f() {}"},
+		{"fn add(x, y = 1) { x + y };", []string{"x", "y"}, map[string]string{"y": "1"}, "fn add(x, y = 1) { (x + y) }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Errorf("length parameters wrong. want %d, got=%d", len(tt.expectedParams), len(function.Parameters))
+			continue
+		}
+
+		for i, ident := range tt.expectedParams {
+			if function.Parameters[i].Value != ident {
+				t.Errorf("parameter %d wrong. want %s, got=%s", i, ident, function.Parameters[i].Value)
+			}
+		}
+
+		if len(function.Defaults) != len(tt.expectedDefaults) {
+			t.Errorf("length defaults wrong. want %d, got=%d", len(tt.expectedDefaults), len(function.Defaults))
+		}
+		for name, expected := range tt.expectedDefaults {
+			if nodeString(function.Defaults[name]) != expected {
+				t.Errorf("default of %s wrong. want %q, got=%q", name, expected, nodeString(function.Defaults[name]))
+			}
+		}
+
+		if function.String() != tt.expectedString {
+			t.Errorf("function.String() wrong. want %q, got=%q", tt.expectedString, function.String())
+		}
+	}
+}
+
+func TestFunctionParameterParsingError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"fn(x = 1, y) {};", "1:11: parameter y without default value follows parameter with default value"},
+		{"fn(x, 1) {};", "1:7: expected next token to be IDENT, got INT instead"},
+		{"fn(x y) {};", "1:6: expected next token to be ), got IDENT instead"},
+		{"macro(x = 1) {};", "1:6: default parameter values are not allowed in macro"},
+		{"fn(...rest, x) {};", "1:7: rest parameter rest must be the last parameter"},
+		{"fn(...rest = 1) {};", "1:12: expected next token to be ), got = instead"},
+		{"fn(x, ...) {};", "1:10: expected next token to be IDENT, got ) instead"},
+		{"macro(...rest) {};", "1:6: rest parameter is not allowed in macro"},
+		{"fn(,) {};", "1:4: expected next token to be IDENT, got , instead"},
+		{"fn(a,,) {};", "1:6: expected next token to be IDENT, got , instead"},
+		{"fn f {};", "1:6: expected next token to be (, got { instead"},
+		{"fn f g() {};", "1:6: expected next token to be (, got IDENT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestNamedFunctionLiteralParsing(t *testing.T) {
+	input := `fn fact(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }(5)`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+
+	function, ok := call.Function.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("call.Function is not ast.FunctionLiteral. got=%T", call.Function)
+	}
+	if function.Name == nil || function.Name.Value != "fact" {
+		t.Fatalf("function.Name wrong. got=%v", function.Name)
+	}
+	if len(function.Parameters) != 1 || function.Parameters[0].Value != "n" {
+		t.Errorf("function literal parameters wrong. got=%v", function.Parameters)
+	}
+	if len(call.Arguments) != 1 {
+		t.Fatalf("wrong length of arguments. got=%d", len(call.Arguments))
+	}
+	testIntegerLiteral(t, call.Arguments[0], 5)
+
+	// 無名関数のNameはnilのまま
+	p = New(lexer.New("fn(x) { x }"))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+	anonymous := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if anonymous.Name != nil {
+		t.Errorf("anonymous.Name is not nil. got=%v", anonymous.Name)
+	}
+}
+
+func TestMacroLiteralParsing(t *testing.T) {
+	input := `macro(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	macro, ok := stmt.Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MacroLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("macro literal parameters wrong. want 2, got=%d", len(macro.Parameters))
+	}
+	if macro.Parameters[0].Value != "x" || macro.Parameters[1].Value != "y" {
+		t.Errorf("macro literal parameters wrong. got=%s, %s", macro.Parameters[0], macro.Parameters[1])
+	}
+
+	if len(macro.Body.Statements) != 1 {
+		t.Fatalf("macro.Body.Statements has not 1 statements. got=%d", len(macro.Body.Statements))
+	}
+	if macro.Body.String() != "(x + y)" {
+		t.Errorf("macro.Body.String() wrong. got=%q", macro.Body.String())
+	}
+
+	if macro.String() != "macro(x, y) { (x + y) }" {
+		t.Errorf("macro.String() wrong. got=%q", macro.String())
+	}
+}
+
+func TestMacroLiteralParameters(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{input: "macro() {};", expectedParams: []string{}},
+		{input: "macro(x) {};", expectedParams: []string{"x"}},
+		{input: "macro(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		macro := stmt.Expression.(*ast.MacroLiteral)
+
+		if len(macro.Parameters) != len(tt.expectedParams) {
+			t.Errorf("length parameters wrong. want %d, got=%d", len(tt.expectedParams), len(macro.Parameters))
+			continue
+		}
+
+		for i, ident := range tt.expectedParams {
+			if macro.Parameters[i].Value != ident {
+				t.Errorf("parameter %d wrong. want %s, got=%s", i, ident, macro.Parameters[i].Value)
+			}
+		}
+	}
+}
+
+func TestMacroLiteralParametersError(t *testing.T) {
+	l := lexer.New("macro(1) {};")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("parser has no errors")
+	}
+	if errors[0] != "1:7: expected next token to be IDENT, got INT instead" {
+		t.Errorf("wrong error message. got=%q", errors[0])
+	}
+}
+
+func TestParsingPrefixExpressions(t *testing.T) {
+	tests := []struct {
+		input        string
+		operator     string
+		integerValue int64
+	}{
+		{"!5;", "!", 5},
+		{"-15;", "-", 15},
+		{"~5;", "~", 5},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		exp, ok := stmt.Expression.(*ast.PrefixExpression)
+		if !ok {
+			t.Fatalf("stmt is not ast.PrefixExpression. got=%T", stmt.Expression)
+		}
+
+		if exp.Operator != tt.operator {
+			t.Fatalf("exp.Operator is not '%s'. got=%s", tt.operator, exp.Operator)
+		}
+
+		if !testIntegerLiteral(t, exp.Right, tt.integerValue) {
+			return
+		}
+	}
+}
+
+func TestParsingInfixExpressions(t *testing.T) {
+	tests := []struct {
+		input      string
+		leftValue  int64
+		operator   string
+		rightValue int64
+	}{
+		{"5 + 5;", 5, "+", 5},
+		{"5 - 5;", 5, "-", 5},
+		{"5 * 5;", 5, "*", 5},
+		{"5 / 5;", 5, "/", 5},
+		{"10 % 3;", 10, "%", 3},
+		{"5 > 5;", 5, ">", 5},
+		{"5 < 5;", 5, "<", 5},
+		{"5 == 5;", 5, "==", 5},
+		{"5 != 5;", 5, "!=", 5},
+		{"5 && 5;", 5, "&&", 5},
+		{"5 || 5;", 5, "||", 5},
+		{"5 & 3;", 5, "&", 3},
+		{"5 | 3;", 5, "|", 3},
+		{"5 ^ 3;", 5, "^", 3},
+		{"1 << 4;", 1, "<<", 4},
+		{"16 >> 2;", 16, ">>", 2},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		exp, ok := stmt.Expression.(*ast.InfixExpression)
+		if !ok {
+			t.Fatalf("exp is not ast.InfixExpression. got=%T", stmt.Expression)
+		}
+
+		if !testIntegerLiteral(t, exp.Left, tt.leftValue) {
+			return
+		}
+
+		if exp.Operator != tt.operator {
+			t.Fatalf("exp.Operator is not '%s'. got=%s", tt.operator, exp.Operator)
+		}
+
+		if !testIntegerLiteral(t, exp.Right, tt.rightValue) {
+			return
+		}
+	}
+}
+
+func TestOperatorPrecedenceParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a + b + c", "((a + b) + c)"},
+		{"a + b - c", "((a + b) - c)"},
+		{"a * b * c", "((a * b) * c)"},
+		{"a * b / c", "((a * b) / c)"},
+		{"a + b / c", "(a + (b / c))"},
+		{"a + b % c", "(a + (b % c))"},
+		{"a * b % c", "((a * b) % c)"},
+		{"a + b * c + d / e - f", "(((a + (b * c)) + (d / e)) - f)"},
+		{"5 > 4 == 3 < 4", "((5 > 4) == (3 < 4))"},
+		{"5 < 4 != 3 > 4", "((5 < 4) != (3 > 4))"},
+		{"3 + 4 * 5 == 3 * 1 + 4 * 5", "((3 + (4 * 5)) == ((3 * 1) + (4 * 5)))"},
+		{"n % 2 == 0", "((n % 2) == 0)"},
+		{"a && b || c", "((a && b) || c)"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a == b && c != d", "((a == b) && (c != d))"},
+		{"a < b || c > d", "((a < b) || (c > d))"},
+		{"a * b[2]", "(a * (b[2]))"},
+		{"a[1 + 1] % b[c]", "((a[(1 + 1)]) % (b[c]))"},
+		{"a[b[0]]", "(a[(b[0])])"},
+		{"1 + (2 + 3) + 4", "((1 + (2 + 3)) + 4)"},
+		{"(5 + 5) * 2", "((5 + 5) * 2)"},
+		{"2 / (5 + 5)", "(2 / (5 + 5))"},
+		{"(a || b) && c", "((a || b) && c)"},
+		{"-a * b", "((-a) * b)"},
+		{"!-a", "(!(-a))"},
+		{"--5", "(-(-5))"},
+		{"5 - -3", "(5 - (-3))"},
+		{"5--3", "(5 - (-3))"},
+		{"-a[0]", "(-(a[0]))"},
+		{"-add(1)", "(-add(1))"},
+		{"!true == false", "((!true) == false)"},
+		{"a | b ^ c & d", "(a | (b ^ (c & d)))"},
+		{"a & b | c", "((a & b) | c)"},
+		{"1 << 2 + 3", "((1 << 2) + 3)"},
+		{"1 << 2 * 3", "(1 << (2 * 3))"},
+		{"a >> 1 << 2", "((a >> 1) << 2)"},
+		{"a & 1 == 0", "((a & 1) == 0)"},
+		{"a | b < c", "((a | b) < c)"},
+		{"a & b && c | d", "((a & b) && (c | d))"},
+		{"-a << 1", "((-a) << 1)"},
+		{"~a & b", "((~a) & b)"},
+		{"~~a", "(~(~a))"},
+		{"!~a", "(!(~a))"},
+		{"~-a", "(~(-a))"},
+		{"a + add(b * c) + d", "((a + add((b * c))) + d)"},
+		{"add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))", "add(a, b, 1, (2 * 3), (4 + 5), add(6, (7 * 8)))"},
+		{"add(a + b + c * d / f + g)", "add((((a + b) + ((c * d) / f)) + g))"},
+		{"a * [1, 2, 3, 4][b * c] * d", "((a * ([1, 2, 3, 4][(b * c)])) * d)"},
+		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := "(1 + 2) * 3;\nlet x = y;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	product := stmt.Expression.(*ast.InfixExpression)
+	sum := product.Left.(*ast.InfixExpression)
+	letStmt := program.Statements[1].(*ast.LetStatement)
+
+	tests := []struct {
+		name          string
+		node          ast.Node
+		expectedStart ast.Position
+		expectedEnd   ast.Position
+	}{
+		{"program", program, ast.Position{Line: 1, Column: 1}, ast.Position{Line: 2, Column: 11}},
+		{"(1 + 2) * 3;", stmt, ast.Position{Line: 1, Column: 1}, ast.Position{Line: 1, Column: 13}},
+		{"(1 + 2) * 3", product, ast.Position{Line: 1, Column: 1}, ast.Position{Line: 1, Column: 12}},
+		{"(1 + 2)", sum, ast.Position{Line: 1, Column: 1}, ast.Position{Line: 1, Column: 8}},
+		{"1", sum.Left, ast.Position{Line: 1, Column: 2}, ast.Position{Line: 1, Column: 3}},
+		{"2", sum.Right, ast.Position{Line: 1, Column: 6}, ast.Position{Line: 1, Column: 7}},
+		{"3", product.Right, ast.Position{Line: 1, Column: 11}, ast.Position{Line: 1, Column: 12}},
+		{"let x = y;", letStmt, ast.Position{Line: 2, Column: 1}, ast.Position{Line: 2, Column: 11}},
+		{"x", letStmt.Name, ast.Position{Line: 2, Column: 5}, ast.Position{Line: 2, Column: 6}},
+		{"y", letStmt.Value, ast.Position{Line: 2, Column: 9}, ast.Position{Line: 2, Column: 10}},
+	}
+
+	for _, tt := range tests {
+		start, end := tt.node.Pos()
+		if start != tt.expectedStart {
+			t.Errorf("%s: start position wrong. expected=%+v, got=%+v", tt.name, tt.expectedStart, start)
+		}
+		if end != tt.expectedEnd {
+			t.Errorf("%s: end position wrong. expected=%+v, got=%+v", tt.name, tt.expectedEnd, end)
+		}
+	}
+}
+
+func TestBlockStatementPosition(t *testing.T) {
+	input := `while (x) {
+  "a" + "b";
+}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.WhileStatement)
+	body := stmt.Body.Statements[0].(*ast.ExpressionStatement)
+
+	tests := []struct {
+		name          string
+		node          ast.Node
+		expectedStart ast.Position
+		expectedEnd   ast.Position
+	}{
+		{"while", stmt, ast.Position{Line: 1, Column: 1}, ast.Position{Line: 3, Column: 2}},
+		{"block", stmt.Body, ast.Position{Line: 1, Column: 11}, ast.Position{Line: 3, Column: 2}},
+		{`"a" + "b"`, body.Expression, ast.Position{Line: 2, Column: 3}, ast.Position{Line: 2, Column: 12}},
+	}
+
+	for _, tt := range tests {
+		start, end := tt.node.Pos()
+		if start != tt.expectedStart {
+			t.Errorf("%s: start position wrong. expected=%+v, got=%+v", tt.name, tt.expectedStart, start)
+		}
+		if end != tt.expectedEnd {
+			t.Errorf("%s: end position wrong. expected=%+v, got=%+v", tt.name, tt.expectedEnd, end)
+		}
+	}
+}
+
+func TestRegisterInfixOperator(t *testing.T) {
+	tests := []struct {
+		precedence int
+		input      string
+		expected   string
+	}{
+		{PRODUCT, "1 + 2 ! 3", "(1 + (2 ! 3))"},
+		{SUM, "1 * 2 ! 3", "((1 * 2) ! 3)"},
+		{EQUALS, "a ! b == c", "((a ! b) == c)"},
+		{LESSGREATER, "a ! b == c", "((a ! b) == c)"},
+		{AND, "a ! b == c", "(a ! (b == c))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.RegisterInfixOperator(token.BANG, tt.precedence, nil)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestRegisterInfixOperatorCustomFn(t *testing.T) {
+	l := lexer.New("a ! b + c")
+	p := New(l)
+	// 右辺を読まない後置演算子として登録する。
+	p.RegisterInfixOperator(token.BANG, CALL, func(left ast.Expression) ast.Expression {
+		return &ast.InfixExpression{
+			Token:    p.curToken,
+			Left:     left,
+			Operator: p.curToken.Literal,
+			Right:    &ast.Identifier{Token: p.curToken, Value: "_"},
+		}
+	})
+	program := p.ParseProgram()
+
+	// 後置演算子の後にはトークンが残るため、"b + c"は別の文として解析される。
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+	if nodeString(program.Statements[0]) != "(a ! _)" {
+		t.Errorf("program.Statements[0] wrong. got=%q", nodeString(program.Statements[0]))
+	}
+}
+
+func TestRegisterInfixOperatorDoesNotAffectOtherParsers(t *testing.T) {
+	p := New(lexer.New("a ! b"))
+	p.RegisterInfixOperator(token.BANG, SUM, nil)
+
+	other := New(lexer.New("a ! b"))
+	if other.peekPrecedence() != LOWEST {
+		t.Fatalf("peekPrecedence wrong. got=%d", other.peekPrecedence())
+	}
+}
+
+func TestNewlineSeparatedStatements(t *testing.T) {
+	tests := []struct {
+		withNewlines   string
+		withSemicolons string
+	}{
+		{"let x = 1\nlet y = x\ny", "let x = 1; let y = x; y;"},
+		{"a\n(b)", "a; (b);"},
+		{"a\n[1, 2]", "a; [1, 2];"},
+		{"a\n-b", "a; -b;"},
+		{"f(x)\n(g)(y)", "f(x); (g)(y);"},
+		{"arr[0]\n!x", "arr[0]; !x;"},
+		{"let s = \"a\nb\" + c\n(d)", "let s = \"a\nb\" + c; (d);"},
+		{"if (x) { a } else { b }\n-1", "if (x) { a } else { b }; -1;"},
+		{"let f = fn(x) {\n  x\n  -x\n}\n(1)", "let f = fn(x) { x; -x; }; (1);"},
+		{"x = y\n[1]", "x = y; [1];"},
+		{"while (true) { break\ncontinue }", "while (true) { break; continue; }"},
+		// 演算子やカンマ、開き括弧で行が終わる場合は次の行に続く。
+		{"1 +\n2 *\n3", "1 + 2 * 3;"},
+		{"f(\n  1,\n  2\n)", "f(1, 2);"},
+		{"[\n  a,\n  b\n][0]", "[a, b][0];"},
+		{"a &&\nb ||\nc", "a && b || c;"},
+		{"let x =\n  1", "let x = 1;"},
+	}
+
+	for _, tt := range tests {
+		expected := parseProgramString(t, tt.withSemicolons)
+		got := parseProgramString(t, tt.withNewlines)
+		if got != expected {
+			t.Errorf("input %q parsed differently from %q.\nexpected=%q\ngot=%q", tt.withNewlines, tt.withSemicolons, expected, got)
+		}
+	}
+}
+
+// 入力を構文解析し、文ごとの文字列表現を改行で区切って返す。
+func parseProgramString(t *testing.T, input string) string {
+	t.Helper()
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	statements := []string{}
+	for _, s := range program.Statements {
+		statements = append(statements, s.String())
+	}
+	return strings.Join(statements, "\n")
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"let x=1+2*3", "let x = 1 + 2 * 3;\n"},
+		{"let x = (1 + 2) * 3;", "let x = (1 + 2) * 3;\n"},
+		{"a - (b - c); (a - b) - c", "a - (b - c);\na - b - c;\n"},
+		{"(-a) * b; -(a * b); !(!x); -f(1)", "-a * b;\n-(a * b);\n!!x;\n-f(1);\n"},
+		{"(a || b) && c | d << 1", "(a || b) && c | d << 1;\n"},
+		{"(a + b)[0]; (f)(x)[1:]; a[:2]", "(a + b)[0];\nf(x)[1:];\na[:2];\n"},
+		{"x += 1", "x = x + 1;\n"},
+		{"const  pi=3", "const pi = 3;\n"},
+		{"let [a,b,]=arr; let {x}=h", "let [a, b] = arr;\nlet {x} = h;\n"},
+		{`let s = "a\"b\\c\n"`, `let s = "a\"b\\c\n";` + "\n"},
+		{"let t = `a${x + 1}\\`\\${b}`", "let t = `a${x + 1}\\`\\${b}`;\n"},
+		{`{"a": 1, "b": [true, null]}`, `{"a": 1, "b": [true, null]};` + "\n"},
+		{"let f = fn(x, y = 2, ...r) { x + y }", "let f = fn(x, y = 2, ...r) {\n  x + y;\n};\n"},
+		{"fn() {}()", "fn() {}();\n"},
+		{"fn(a, b) { return b,a*2 }", "fn(a, b) {\n  return b, a * 2;\n};\n"},
+		{
+			"if (x < 1) { a } else if (x > 2) { b } else { if (y) { c } }",
+			"if (x < 1) {\n  a;\n} else if (x > 2) {\n  b;\n} else {\n  if (y) {\n    c;\n  }\n}\n",
+		},
+		{"if (x) { a }; -1", "if (x) {\n  a;\n};\n-1;\n"},
+		{"if (x) { a }; y", "if (x) {\n  a;\n}\ny;\n"},
+		{
+			"switch (x) { case 1, 2: a; b default: c }",
+			"switch (x) {\ncase 1, 2:\n  a;\n  b;\ndefault:\n  c;\n}\n",
+		},
+		{
+			"while (i < 3) { i = i + 1; if (i == 2) { break } continue; }",
+			"while (i < 3) {\n  i = i + 1;\n  if (i == 2) {\n    break;\n  }\n  continue;\n}\n",
+		},
+		{"for (let i = 0; i < 3; i += 1) { puts(i) } for (;;) {}", "for (let i = 0; i < 3; i = i + 1) {\n  puts(i);\n}\nfor (; ; ) {}\n"},
+		{"map(a, fn(x) { x * 2 })", "map(a, fn(x) {\n  x * 2;\n});\n"},
+		{"try { 1 / 0 } catch (e) { 0 }\n-x", "try {\n  1 / 0;\n} catch (e) {\n  0;\n};\n-x;\n"},
+		{"fn fact(n) { n }(5)", "fn fact(n) {\n  n;\n}(5);\n"},
+		{"macro(a) { quote(unquote(a)) }", "macro(a) {\n  quote(unquote(a));\n};\n"},
+		{"let a = 1;\n\n\n\nlet b = 2;\nlet c = 3;", "let a = 1;\n\nlet b = 2;\nlet c = 3;\n"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if formatted := program.Format(); formatted != tt.expected {
+			t.Errorf("Format wrong for %q.\nexpected=%q\ngot=%q", tt.input, tt.expected, formatted)
+		}
+	}
+}
+
+// 整形した結果を構文解析して再び整形しても変わらず、ASTも元と同じであることを確かめる。
+func TestFormatIsIdempotent(t *testing.T) {
+	inputs := []string{
+		"let add = fn(a, b) { a + b }; let r = add(1, 2) * -3;",
+		"let fib = fn(n) { if (n < 2) { return n; } fib(n - 1) + fib(n - 2) };\n\nputs(fib(10));",
+		"if (a) { 1 } else if (b) { 2 } else { 3 }; [1, 2][0]",
+		"switch (x % 3) { case 0: \"fizz\" case 1, 2: switch (y) { default: x } }",
+		"let h = {\"b\": 2, \"a\": fn() { 1 }}; h[\"a\"]()",
+		"for (let i = 0; i < 10; i += 2) { while (true) { if (i & 1 == 0) { break; } } }",
+		"let s = `x=${x} \\${y} \\` ${`nested ${z}`}`",
+		"a - b - c; a - (b - c); a * (b + c) / d % e; ~(a ^ b) >> 1",
+	}
+
+	for _, input := range inputs {
+		p := New(lexer.New(input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		formatted := program.Format()
+
+		p = New(lexer.New(formatted))
+		reparsed := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if reparsed.Format() != formatted {
+			t.Errorf("Format is not idempotent for %q.\nfirst=%q\nsecond=%q", input, formatted, reparsed.Format())
+		}
+		// ハッシュリテラルのStringはキーの順が決まらないため、ASTの比較からは除く。
+		if reparsed.String() != program.String() && !strings.Contains(input, "{\"") {
+			t.Errorf("formatted source has different AST for %q.\nexpected=%q\ngot=%q", input, program.String(), reparsed.String())
+		}
+	}
+}
+
+// 別々に生成したParserを並行して使っても、互いに干渉せず結果が変わらないことを確かめる。
+// データ競合の検出には`go test -race`で実行する。
+func TestParseConcurrently(t *testing.T) {
+	sources := make([]string, 10)
+	for i := range sources {
+		sources[i] = fmt.Sprintf(`let x%d = %d * (y + %d);
+let f = fn(a, b = %d) { if (a < b) { a } else { b ! a } };
+f(x%d, "s%d")[0];`, i, i, i, i, i, i)
+	}
+
+	// 半分のParserでは独自の中置演算子を登録し、Parserごとの状態が共有されないことも確かめる。
+	parse := func(i int) (string, int) {
+		p := New(lexer.New(sources[i]))
+		if i%2 == 0 {
+			p.RegisterInfixOperator(token.BANG, PRODUCT, nil)
+		}
+		program := p.ParseProgram()
+		return program.String(), len(p.Errors())
+	}
+
+	expected := make([]string, len(sources))
+	expectedErrors := make([]int, len(sources))
+	for i := range sources {
+		expected[i], expectedErrors[i] = parse(i)
+	}
+
+	for n := 0; n < 10; n++ {
+		got := make([]string, len(sources))
+		gotErrors := make([]int, len(sources))
+
+		var wg sync.WaitGroup
+		for i := range sources {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				got[i], gotErrors[i] = parse(i)
+			}(i)
+		}
+		wg.Wait()
+
+		for i := range sources {
+			if got[i] != expected[i] {
+				t.Errorf("sources[%d] parsed differently. expected=%q, got=%q", i, expected[i], got[i])
+			}
+			if gotErrors[i] != expectedErrors[i] {
+				t.Errorf("sources[%d] wrong number of errors. expected=%d, got=%d", i, expectedErrors[i], gotErrors[i])
+			}
+		}
+	}
+}
+
+func TestParsingArrayLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"[1, 2 * 2, 3 + 3]", []string{"1", "(2 * 2)", "(3 + 3)"}},
+		{"[]", []string{}},
+		{"[1, 2,]", []string{"1", "2"}},
+		{`["a", [1]]`, []string{"a", "[1]"}},
+		{"[-1, -2]", []string{"(-1)", "(-2)"}},
+		{"[1, -2 - 3]", []string{"1", "((-2) - 3)"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		array, ok := stmt.Expression.(*ast.ArrayLiteral)
+		if !ok {
+			t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+		}
+
+		if len(array.Elements) != len(tt.expected) {
+			t.Fatalf("len(array.Elements) not %d. got=%d", len(tt.expected), len(array.Elements))
+		}
+
+		for i, expected := range tt.expected {
+			if array.Elements[i].String() != expected {
+				t.Errorf("array.Elements[%d] is not %q. got=%q", i, expected, array.Elements[i].String())
+			}
+		}
+	}
+}
+
+func TestIfExpression(t *testing.T) {
+	tests := []struct {
+		input               string
+		expectedCondition   string
+		expectedConsequence string
+		expectedAlternative string
+		expectedString      string
+	}{
+		{"if (x < y) { x }", "(x < y)", "x", "", "if (x < y) { x }"},
+		{"if (x < y) { x } else { y }", "(x < y)", "x", "y", "if (x < y) { x } else { y }"},
+		{"if (x < y) { x } else { if (x > y) { y } }", "(x < y)", "x", "if (x > y) { y }", "if (x < y) { x } else { if (x > y) { y } }"},
+		{"if (x) { } else { if (!y) { a } }", "x", "", "if (!y) { a }", "if (x) {} else { if (!y) { a } }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		exp, ok := stmt.Expression.(*ast.IfExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+		}
+
+		if exp.Condition.String() != tt.expectedCondition {
+			t.Errorf("exp.Condition wrong. want %q, got=%q", tt.expectedCondition, exp.Condition.String())
+		}
+		if exp.Consequence.String() != tt.expectedConsequence {
+			t.Errorf("exp.Consequence wrong. want %q, got=%q", tt.expectedConsequence, exp.Consequence.String())
+		}
+		if tt.expectedAlternative == "" {
+			if exp.Alternative != nil {
+				t.Errorf("exp.Alternative was not nil. got=%q", exp.Alternative.String())
+			}
+		} else if exp.Alternative == nil || exp.Alternative.String() != tt.expectedAlternative {
+			t.Errorf("exp.Alternative wrong. want %q, got=%q", tt.expectedAlternative, nodeString(exp.Alternative))
+		}
+		if _, ok := exp.ElseIf(); ok {
+			t.Errorf("exp.ElseIf() is true for %q", tt.input)
+		}
+
+		if exp.String() != tt.expectedString {
+			t.Errorf("exp.String() wrong. want %q, got=%q", tt.expectedString, exp.String())
+		}
+
+		// 文字列にした結果を再び構文解析して、同じ文字列になることを確かめる。
+		reparsed := New(lexer.New(exp.String()))
+		reparsedProgram := reparsed.ParseProgram()
+		checkParserErrors(t, reparsed)
+		if reparsedProgram.String() != tt.expectedString {
+			t.Errorf("reparsed String() wrong. want %q, got=%q", tt.expectedString, reparsedProgram.String())
+		}
+	}
+}
+
+// ブロックを持つ文と式の文字列表現が波括弧と空白を含み、再び構文解析できることを確かめる。
+func TestBlockNodeString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (x < 3) { x += 1; }", "while (x < 3) { x = (x + 1) }"},
+		{"while (ok) {}", "while (ok) {}"},
+		{"for (let i = 0; i < 3; i += 1) { puts(i); }", "for (let i = 0; (i < 3); i = (i + 1)) { puts(i) }"},
+		{"for (;;) {}", "for (; ; ) {}"},
+		{"switch (x) { case 1: a default: b }", "switch (x) { case 1: a default: b }"},
+		{"switch (x) { case 1: }", "switch (x) { case 1: }"},
+		{"try { f() } catch (e) { g(e) }", "try { f() } catch (e) { g(e) }"},
+		{"fn(x) { fn(y) { x + y } }", "fn(x) { fn(y) { (x + y) } }"},
+		{"if (x) { a; b }", "if (x) { a; b }"},
+		{"if (x) { a } else { b; c; }", "if (x) { a } else { b; c }"},
+		{"while (x) { a; b; }", "while (x) { a; b }"},
+		{"for (;;) { let a = 1; break; }", "for (; ; ) { let a = 1; break }"},
+		{"switch (x) { case 1: a; b default: c; d }", "switch (x) { case 1: a; b default: c; d }"},
+		{"try { let a = f(); a } catch (e) { puts(e); e }", "try { let a = f(); a } catch (e) { puts(e); e }"},
+		{"fn() { let a = 1; a }", "fn() { let a = 1; a }"},
+		{"fn(x) { if (x) { return 1; } return 2; }", "fn(x) { if (x) { return 1 }; return 2 }"},
+		{"fn() { while (x) { a; b } c }", "fn() { while (x) { a; b } c }"},
+		{"fn() { for (;;) { a } d }", "fn() { for (; ; ) { a } d }"},
+		{"macro(x) { x }", "macro(x) { x }"},
+		{"macro(a, b) { quote(unquote(a) + unquote(b)); }", "macro(a, b) { quote((unquote(a) + unquote(b))) }"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("String() wrong for %q. want %q, got=%q", tt.input, tt.expected, program.String())
+		}
+
+		reparsed := New(lexer.New(program.String()))
+		reparsedProgram := reparsed.ParseProgram()
+		checkParserErrors(t, reparsed)
+		if reparsedProgram.String() != tt.expected {
+			t.Errorf("reparsed String() wrong for %q. want %q, got=%q", tt.input, tt.expected, reparsedProgram.String())
+		}
+	}
+}
+
+func TestElseIfExpression(t *testing.T) {
+	input := `if (x < 0) { a } else if (x == 0) { b } else { c }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+	}
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+
+	elseIf, ok := exp.ElseIf()
+	if !ok {
+		t.Fatalf("exp.Alternative is not else if. got=%q", nodeString(exp.Alternative))
+	}
+	if elseIf.Condition.String() != "(x == 0)" {
+		t.Errorf("elseIf.Condition wrong. got=%q", elseIf.Condition.String())
+	}
+	if elseIf.Consequence.String() != "b" {
+		t.Errorf("elseIf.Consequence wrong. got=%q", elseIf.Consequence.String())
+	}
+	if elseIf.Alternative == nil || elseIf.Alternative.String() != "c" {
+		t.Errorf("elseIf.Alternative wrong. got=%q", nodeString(elseIf.Alternative))
+	}
+	if _, ok := elseIf.ElseIf(); ok {
+		t.Errorf("elseIf.Alternative is else if")
+	}
+
+	start, end := elseIf.Pos()
+	if start != (ast.Position{Line: 1, Column: 23}) || end != (ast.Position{Line: 1, Column: 51}) {
+		t.Errorf("elseIf.Pos() wrong. got=%v, %v", start, end)
+	}
+
+	expected := "if (x < 0) { a } else if (x == 0) { b } else { c }"
+	if exp.String() != expected {
+		t.Errorf("exp.String() wrong. want %q, got=%q", expected, exp.String())
+	}
+}
+
+func TestIfExpressionError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"if x { a }", "1:4: expected next token to be (, got IDENT instead"},
+		{"if (x) { a } else b", "1:19: expected next token to be {, got IDENT instead"},
+		{"if (x) { a } else if { b }", "1:22: expected next token to be (, got { instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestCallExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedFunction string
+		expectedArgs     []string
+	}{
+		{"add();", "add", []string{}},
+		{"add(1, 2 * 3, 4 + 5);", "add", []string{"1", "(2 * 3)", "(4 + 5)"}},
+		{"add(1, 2,);", "add", []string{"1", "2"}},
+		{"fn(x) { x }(5);", "fn(x) { x }", []string{"5"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		exp, ok := stmt.Expression.(*ast.CallExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+		}
+
+		if exp.Function.String() != tt.expectedFunction {
+			t.Errorf("exp.Function wrong. want %q, got=%q", tt.expectedFunction, exp.Function.String())
+		}
+
+		if len(exp.Arguments) != len(tt.expectedArgs) {
+			t.Fatalf("wrong length of arguments. want %d, got=%d", len(tt.expectedArgs), len(exp.Arguments))
+		}
+
+		for i, arg := range tt.expectedArgs {
+			if exp.Arguments[i].String() != arg {
+				t.Errorf("argument %d wrong. want %q, got=%q", i, arg, exp.Arguments[i].String())
+			}
+		}
+	}
+}
+
+func TestCallExpressionParsingError(t *testing.T) {
+	tests := []string{
+		"add(1, 2",
+		"add(1 2)",
+		"add(,)",
+		"add(1,,)",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestParsingArrayLiteralsError(t *testing.T) {
+	tests := []string{
+		"[1, 2",
+		"[1 2]",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestParsingIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedLeft  string
+		expectedIndex string
+	}{
+		{"myArray[1 + 1]", "myArray", "(1 + 1)"},
+		{`h["key"]`, "h", "key"},
+		{"[1, 2, 3][1]", "[1, 2, 3]", "1"},
+		{`{"key": 1}["key"]`, `{key:1}`, "key"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+		if !ok {
+			t.Fatalf("exp not *ast.IndexExpression. got=%T", stmt.Expression)
+		}
+
+		if indexExp.Left.String() != tt.expectedLeft {
+			t.Errorf("indexExp.Left is not %q. got=%q", tt.expectedLeft, indexExp.Left.String())
+		}
+
+		if indexExp.Index.String() != tt.expectedIndex {
+			t.Errorf("indexExp.Index is not %q. got=%q", tt.expectedIndex, indexExp.Index.String())
+		}
+	}
+}
+
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedStart string
+		expectedEnd   string
+		expected      string
+	}{
+		{`"hello"[1:3]`, "1", "3", "(hello[1:3])"},
+		{"s[:n - 1]", "", "(n - 1)", "(s[:(n - 1)])"},
+		{"s[2:]", "2", "", "(s[2:])"},
+		{"s[:]", "", "", "(s[:])"},
+		{"a[1:2][0]", "1", "2", "((a[1:2])[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("program.String() wrong. expected=%q, got=%q", tt.expected, program.String())
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		sliceExp, ok := stmt.Expression.(*ast.SliceExpression)
+		if !ok {
+			indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+			if !ok {
+				t.Fatalf("exp not *ast.SliceExpression. got=%T", stmt.Expression)
+			}
+			sliceExp = indexExp.Left.(*ast.SliceExpression)
+		}
+
+		if nodeString(sliceExp.Start) != tt.expectedStart {
+			t.Errorf("sliceExp.Start is not %q. got=%q", tt.expectedStart, nodeString(sliceExp.Start))
+		}
+		if nodeString(sliceExp.End) != tt.expectedEnd {
+			t.Errorf("sliceExp.End is not %q. got=%q", tt.expectedEnd, nodeString(sliceExp.End))
+		}
+	}
+}
+
+func TestParsingSliceExpressionsError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"s[1:2", "1:6: expected next token to be ], got EOF instead"},
+		{"s[1:2:3]", "1:6: expected next token to be ], got : instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestParsingHashLiteralsStringKeys(t *testing.T) {
+	input := `{"one": 1, "two": 2, "three": 3}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+
+	expected := map[string]int64{
+		"one":   1,
+		"two":   2,
+		"three": 3,
+	}
+
+	for key, value := range hash.Pairs {
+		literal, ok := key.(*ast.StringLiteral)
+		if !ok {
+			t.Errorf("key is not ast.StringLiteral. got=%T", key)
+		}
+
+		expectedValue := expected[literal.String()]
+
+		testIntegerLiteral(t, value, expectedValue)
+	}
+}
+
+func TestParsingHashLiteralsIntegerKeys(t *testing.T) {
+	input := `{1: "one", 2: "two"}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	expected := map[int64]string{
+		1: "one",
+		2: "two",
+	}
+
+	if len(hash.Pairs) != len(expected) {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+
+	for key, value := range hash.Pairs {
+		literal, ok := key.(*ast.IntegerLiteral)
+		if !ok {
+			t.Errorf("key is not ast.IntegerLiteral. got=%T", key)
+			continue
+		}
+
+		if value.String() != expected[literal.Value] {
+			t.Errorf("value for key %d is not %q. got=%q", literal.Value, expected[literal.Value], value.String())
+		}
+	}
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	input := "{}"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 0 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+}
+
+func TestParsingHashLiteralsWithExpressions(t *testing.T) {
+	input := `{"one": 0 + 1, "two": 10 - 8, "three": 15 / 5}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+
+	expected := map[string]string{
+		"one":   "(0 + 1)",
+		"two":   "(10 - 8)",
+		"three": "(15 / 5)",
+	}
+
+	for key, value := range hash.Pairs {
+		literal, ok := key.(*ast.StringLiteral)
+		if !ok {
+			t.Errorf("key is not ast.StringLiteral. got=%T", key)
+			continue
+		}
+
+		if value.String() != expected[literal.String()] {
+			t.Errorf("value for key %q is not %q. got=%q", literal.String(), expected[literal.String()], value.String())
+		}
+	}
+}
+
+func TestParsingHashLiteralsDuplicateKeys(t *testing.T) {
+	tests := []string{
+		`{"a": 1, "a": 2}`,
+		`{1: "a", 0x1: "b"}`,
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestParsingSpreadExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[...a, 3]", "[...a, 3]"},
+		{"[1, ...f(x), ...[2, 3],]", "[1, ...f(x), ...[2, 3]]"},
+		{`{...h}`, `{...h}`},
+		{`{...h, "k": 1}`, `{...h, "k": 1}`},
+		{`{"k": 1, ...a + b}`, `{"k": 1, ...a + b}`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		// ハッシュの要素の順はmapからは決まらないため、ソース上の位置の順に並べるFormatで比べる。
+		if got := strings.TrimSuffix(program.Format(), ";\n"); got != tt.expected {
+			t.Errorf("%q: wrong result. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestParsingSpreadExpressionsError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"f(...a)", "1:3: no prefix parse function for ... found"},
+		{"[...]", "1:5: no prefix parse function for ] found"},
+		{"{...h: 1}", "1:6: expected next token to be ,, got : instead"},
+		{"{...h 1}", "1:7: expected next token to be ,, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if errors[0] != tt.expectedError {
+			t.Errorf("input %q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestErr(t *testing.T) {
+	p := New(lexer.New("let x = 1;"))
+	p.ParseProgram()
+	if err := p.Err(); err != nil {
+		t.Fatalf("p.Err() should be nil. got=%v", err)
+	}
+
+	p = New(lexer.New("let x 5;\n  let = 10;\nlet y = ;"))
+	p.ParseProgram()
+
+	err := p.Err()
+	if err == nil {
+		t.Fatalf("p.Err() returned nil")
+	}
+	if err.Error() != strings.Join(p.Errors(), "\n") {
+		t.Errorf("err.Error() wrong. got=%q", err.Error())
+	}
+
+	// errors.Unwrapで残りのエラーをたどり、それぞれをerrors.Asで取り出す。
+	var got []ParseError
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		var parseErr *ParseError
+		if !errors.As(e, &parseErr) {
+			t.Fatalf("errors.As failed for %v", e)
+		}
+		got = append(got, *parseErr)
+	}
+
+	expected := p.StructuredErrors()
+	if len(got) != len(expected) {
+		t.Fatalf("wrong number of unwrapped errors. want=%d, got=%d", len(expected), len(got))
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("errors[%d] is not %+v. got=%+v", i, expected[i], got[i])
+		}
+	}
+	if got[1].Line != 2 || got[1].Column != 7 {
+		t.Errorf("second error position wrong. got=%d:%d", got[1].Line, got[1].Column)
+	}
+
+	// 取り出したエラーを書き換えても、Parserのエラーは変わらない。
+	var first *ParseError
+	errors.As(err, &first)
+	first.Message = "changed"
+	if p.StructuredErrors()[0].Message == "changed" {
+		t.Errorf("p.Err() shares errors with the parser")
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"let x = ;",
+			"1:9: no prefix parse function for ; found\n" +
+				"  let x = ;\n" +
+				"          ^",
+		},
+		{
+			"let a = 1;\nlet b = 2;\nlet = 3;",
+			"3:5: expected next token to be IDENT, got = instead\n" +
+				"  let = 3;\n" +
+				"      ^",
+		},
+		{
+			"fn() {\n\tlet\tx 1;\n}",
+			"2:8: expected next token to be =, got INT instead\n" +
+				"  \tlet\tx 1;\n" +
+				"  \t   \t  ^",
+		},
+		{
+			"let s = \"あい\" + ;\r\nx",
+			"1:16: no prefix parse function for ; found\n" +
+				"  let s = \"あい\" + ;\n" +
+				"                 ^",
+		},
+		{
+			"let x = 1 +",
+			"1:12: no prefix parse function for EOF found\n" +
+				"  let x = 1 +\n" +
+				"             ^",
+		},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.StructuredErrors()
+		if len(errors) == 0 {
+			t.Errorf("input %q: parser has no errors", tt.input)
+			continue
+		}
+		if got := FormatError(tt.input, errors[0]); got != tt.expected {
+			t.Errorf("input %q: FormatError wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, got)
+		}
+	}
+
+	// エラーの行がソースに無い場合はメッセージだけを返す。
+	err := ParseError{Message: "unexpected", Line: 3, Column: 1}
+	if got := FormatError("x", err); got != "3:1: unexpected" {
+		t.Errorf("FormatError for missing line wrong. got=%q", got)
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	input := "let x 5;\n  let = 10;"
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	expected := []ParseError{
+		{Message: "expected next token to be =, got INT instead", Line: 1, Column: 7},
+		{Message: "expected next token to be IDENT, got = instead", Line: 2, Column: 7},
+	}
+
+	errors := p.StructuredErrors()
+	if len(errors) != len(expected) {
+		t.Fatalf("parser has wrong number of errors. want=%d, got=%d (%q)", len(expected), len(errors), p.Errors())
+	}
+
+	for i, e := range expected {
+		if errors[i] != e {
+			t.Errorf("errors[%d] is not %+v. got=%+v", i, e, errors[i])
+		}
+	}
+
+	msgs := p.Errors()
+	if msgs[0] != "1:7: expected next token to be =, got INT instead" {
+		t.Errorf("p.Errors()[0] wrong. got=%q", msgs[0])
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	input := `
+	let x 5 + 5;
+	let y = 10;
+	while (y) {
+		let = 1;
+		y;
+	}
+	let z = y;
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	expected := []string{
+		"2:8: expected next token to be =, got INT instead",
+		"5:7: expected next token to be IDENT, got = instead",
+	}
+
+	errors := p.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("parser has wrong number of errors. want=%d, got=%d (%q)", len(expected), len(errors), errors)
+	}
+
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("errors[%d] is not %q. got=%q", i, msg, errors[i])
+		}
+	}
+
+	// 構文エラーを含む文だけが捨てられ、残りの文は解析される。
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 3, len(program.Statements))
+	}
+
+	while, ok := program.Statements[1].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not ast.WhileStatement. got=%T", program.Statements[1])
+	}
+
+	if len(while.Body.Statements) != 1 {
+		t.Errorf("while.Body.Statements does not contain %d statements. got=%d", 1, len(while.Body.Statements))
+	}
+
+	if !testLetStatement(t, program.Statements[2], "z") {
+		return
+	}
+}
+
+// 式が値valueを持つ整数リテラルであることを検査する。
+func testIntegerLiteral(t *testing.T, il ast.Expression, value int64) bool {
+	integ, ok := il.(*ast.IntegerLiteral)
+	if !ok {
+		t.Errorf("il not *ast.IntegerLiteral. got=%T", il)
+		return false
+	}
+
+	if integ.Value != value {
+		t.Errorf("integ.Value not %d. got=%d", value, integ.Value)
+		return false
+	}
+
+	if integ.TokenLiteral() != fmt.Sprintf("%d", value) {
+		t.Errorf("integ.TokenLiteral not %d. got=%s", value, integ.TokenLiteral())
+		return false
+	}
+
+	return true
+}
+
 // Parserのエラーをチェックして、エラーがあればテストエラーとして出力し、テストを停止させる。
 func checkParserErrors(t *testing.T, p *Parser) {
 	errors := p.Errors()
@@ -0,0 +1,56 @@
+package repl
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/term"
+	"local.packages/object"
+)
+
+// ANSIエスケープシーケンスによる文字色
+const (
+	COLOR_RESET   = "\x1b[0m"
+	COLOR_RED     = "\x1b[31m"
+	COLOR_GREEN   = "\x1b[32m"
+	COLOR_YELLOW  = "\x1b[33m"
+	COLOR_BLUE    = "\x1b[34m"
+	COLOR_MAGENTA = "\x1b[35m"
+	COLOR_CYAN    = "\x1b[36m"
+	COLOR_GRAY    = "\x1b[90m"
+)
+
+// オブジェクトの型と表示に使う色のマッピング
+// 含まれない型は色を付けない。
+var objectColors = map[object.ObjectType]string{
+	object.ERROR_OBJ:    COLOR_RED,
+	object.INTEGER_OBJ:  COLOR_GREEN,
+	object.FLOAT_OBJ:    COLOR_GREEN,
+	object.STRING_OBJ:   COLOR_YELLOW,
+	object.BOOLEAN_OBJ:  COLOR_BLUE,
+	object.NULL_OBJ:     COLOR_GRAY,
+	object.FUNCTION_OBJ: COLOR_CYAN,
+	object.BUILTIN_OBJ:  COLOR_CYAN,
+	object.MACRO_OBJ:    COLOR_MAGENTA,
+}
+
+// objの表示に、型に応じた色を付けて返す。
+func colorize(obj object.Object) string {
+	color, ok := objectColors[obj.Type()]
+	if !ok {
+		return obj.Inspect()
+	}
+	return paint(obj.Inspect(), color)
+}
+
+// sをcolorの色で表示する文字列を返す。
+func paint(s, color string) string {
+	return color + s + COLOR_RESET
+}
+
+// 出力先が端末であればtrueを返す。
+// パイプやファイルに出力する場合は、エスケープシーケンスが混ざらないよう色を付けない。
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
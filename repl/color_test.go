@@ -0,0 +1,70 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"local.packages/object"
+)
+
+func TestColorize(t *testing.T) {
+	tests := []struct {
+		obj      object.Object
+		expected string
+	}{
+		{&object.Error{Message: "boom"}, COLOR_RED + "ERROR: boom" + COLOR_RESET},
+		{object.NewInteger(5), COLOR_GREEN + "5" + COLOR_RESET},
+		{&object.String{Value: "hi"}, COLOR_YELLOW + "hi" + COLOR_RESET},
+		{object.TRUE, COLOR_BLUE + "true" + COLOR_RESET},
+		{object.NULL, COLOR_GRAY + "null" + COLOR_RESET},
+		// 色の決まっていない型は色を付けない。
+		{&object.Array{Elements: []object.Object{object.NewInteger(1)}}, "[1]"},
+	}
+
+	for _, tt := range tests {
+		if got := colorize(tt.obj); got != tt.expected {
+			t.Errorf("colorize(%s) wrong. expected=%q, got=%q", tt.obj.Inspect(), tt.expected, got)
+		}
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	if isTerminal(&bytes.Buffer{}) {
+		t.Errorf("bytes.Buffer is terminal")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if isTerminal(w) {
+		t.Errorf("pipe is terminal")
+	}
+}
+
+func TestPrintParserErrorsColor(t *testing.T) {
+	var plain, colored bytes.Buffer
+	printParserErrors(&plain, []string{"1:1: oops"}, false)
+	printParserErrors(&colored, []string{"1:1: oops"}, true)
+
+	if plain.String() != "\t1:1: oops\n" {
+		t.Errorf("plain output wrong. got=%q", plain.String())
+	}
+	if colored.String() != "\t"+COLOR_RED+"1:1: oops"+COLOR_RESET+"\n" {
+		t.Errorf("colored output wrong. got=%q", colored.String())
+	}
+}
+
+func TestStartASTJSONNoColorOnPipe(t *testing.T) {
+	var out bytes.Buffer
+	StartASTJSON(strings.NewReader("let = 5;\n"), &out)
+
+	if strings.Contains(out.String(), "\x1b[") {
+		t.Errorf("output to non-terminal contains escape sequence. got=%q", out.String())
+	}
+}
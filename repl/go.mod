@@ -1,3 +1,7 @@
 module okuzawats.com/go/repl
 
-go 1.19
+go 1.23.0
+
+require golang.org/x/term v0.32.0
+
+require golang.org/x/sys v0.33.0 // indirect
@@ -0,0 +1,90 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HISTORY_FILE ホームディレクトリに置く履歴ファイルの名前
+const HISTORY_FILE = ".monkey_history"
+
+// MAX_HISTORY 保持する履歴の最大件数
+const MAX_HISTORY = 1000
+
+// History 入力した行の履歴
+// term.Historyを満たし、上下キーで過去の入力を呼び出せるようにする。
+type History struct {
+	entries []string // 古いものから順に並べる
+}
+
+// Add 空行と直前と同じ行は記録しない。最大件数を超えた場合は古いものから捨てる。
+func (h *History) Add(entry string) {
+	if strings.TrimSpace(entry) == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == entry {
+		return
+	}
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > MAX_HISTORY {
+		h.entries = h.entries[len(h.entries)-MAX_HISTORY:]
+	}
+}
+
+// Len 記録している件数を返す。
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// At 0を最新として、idx番目に新しい行を返す。
+func (h *History) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
+
+// HistoryPath 履歴ファイルのパスを返す。
+func HistoryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, HISTORY_FILE), nil
+}
+
+// LoadHistory 履歴ファイルを読み込む。ファイルが存在しない場合は空の履歴を返す。
+func LoadHistory(path string) (*History, error) {
+	h := &History{}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.Add(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Save 履歴を古いものから1行ずつファイルに書き出す。
+func (h *History) Save(path string) error {
+	var b strings.Builder
+	for _, entry := range h.entries {
+		b.WriteString(entry)
+		b.WriteString("\n")
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
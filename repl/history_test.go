@@ -0,0 +1,72 @@
+package repl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryAdd(t *testing.T) {
+	h := &History{}
+	h.Add("let x = 1;")
+	h.Add("")
+	h.Add("x;")
+	h.Add("x;")
+
+	expected := []string{"x;", "let x = 1;"}
+	if h.Len() != len(expected) {
+		t.Fatalf("h.Len() wrong. expected=%d, got=%d", len(expected), h.Len())
+	}
+
+	for i, entry := range expected {
+		if h.At(i) != entry {
+			t.Errorf("h.At(%d) wrong. expected=%q, got=%q", i, entry, h.At(i))
+		}
+	}
+}
+
+func TestHistorySaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory returned error: %s", err)
+	}
+	if h.Len() != 0 {
+		t.Fatalf("history loaded from missing file is not empty. got=%d", h.Len())
+	}
+
+	h.Add("let x = 1;")
+	h.Add("x + 2;")
+	if err := h.Save(path); err != nil {
+		t.Fatalf("Save returned error: %s", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile returned error: %s", err)
+	}
+	if string(b) != "let x = 1;\nx + 2;\n" {
+		t.Errorf("history file wrong. got=%q", b)
+	}
+
+	loaded, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory returned error: %s", err)
+	}
+	if loaded.Len() != 2 || loaded.At(0) != "x + 2;" || loaded.At(1) != "let x = 1;" {
+		t.Errorf("loaded history wrong. got=%v", loaded.entries)
+	}
+}
+
+func TestHistoryMaxEntries(t *testing.T) {
+	h := &History{}
+	for i := 0; i < MAX_HISTORY+10; i++ {
+		h.Add(fmt.Sprintf("%d;", i))
+	}
+
+	if h.Len() != MAX_HISTORY {
+		t.Errorf("h.Len() wrong. expected=%d, got=%d", MAX_HISTORY, h.Len())
+	}
+}
@@ -1,31 +1,240 @@
 package repl
 
 import (
-	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"strings"
 
+	"local.packages/ast"
 	"local.packages/lexer"
+	"local.packages/object"
+	"local.packages/parser"
 	"local.packages/token"
 )
 
 const PROMPT = ">> "
 
+// CONTINUATION_PROMPT 括弧が閉じていない入力の続きを促すプロンプト
+const CONTINUATION_PROMPT = "... "
+
+// LOAD_COMMAND ファイルを読み込んで入力として扱うREPLのコマンド
+// 評価器がまだ無いため、ファイルの定義はセッションに残らず、内容のトークン列やASTを表示するだけ。
+const LOAD_COMMAND = ".load"
+
+// TYPE_COMMAND 式の型を表示するREPLのコマンド
+const TYPE_COMMAND = ".type"
+
+// Start REPLを開始する。評価器ができるまでは、入力を字句解析した結果を表示する。
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+	StartLexer(in, out)
+}
+
+// StartLexer 入力を字句解析し、トークンの種別とリテラルを1トークンずつ出力する。
+func StartLexer(in io.Reader, out io.Writer) {
+	startLexer(in, out, false)
+}
+
+// StartStats Startと同じく入力を処理し、入力ごとに字句解析にかかった時間とアロケーションの回数を出力する。
+// 評価器ができるまでは字句解析とトークンの出力だけを計測するため、統計には「lexer」と付けて出力する。
+func StartStats(in io.Reader, out io.Writer) {
+	startLexer(in, out, true)
+}
+
+// 入力を字句解析してトークンを出力する。withStatsがtrueの場合は入力ごとに字句解析の統計を出力する。
+func startLexer(in io.Reader, out io.Writer, withStats bool) {
+	color := isTerminal(out)
+	r, out := newLineReader(in, out)
+	defer r.Close()
+
+	for {
+		input, ok := readInput(r, out)
+		if !ok {
+			return
+		}
+
+		input, ok = expandLoadCommand(input, out)
+		if !ok {
+			continue
+		}
+
+		if runTypeCommand(input, out, color) {
+			continue
+		}
+
+		printTokens := func() {
+			l := lexer.New(input)
+
+			for t := l.NextToken(); t.Type != token.EOF; t = l.NextToken() {
+				fmt.Fprintf(out, "{Type:%s Literal:%s}\n", t.Type, t.Literal)
+			}
+		}
+
+		// 統計を取らない場合は、計測によるプログラム全体の一時停止を避ける。
+		if withStats {
+			printStats(out, measure("lexer", printTokens), color)
+		} else {
+			printTokens()
+		}
+	}
+}
+
+// StartASTJSON 入力を1行ずつ構文解析し、ASTをJSONとして出力する。
+// 出力先が端末の場合、構文エラーは赤で表示する。
+func StartASTJSON(in io.Reader, out io.Writer) {
+	color := isTerminal(out)
+	r, out := newLineReader(in, out)
+	defer r.Close()
 
 	for {
-		fmt.Printf(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		input, ok := readInput(r, out)
+		if !ok {
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
+		input, ok = expandLoadCommand(input, out)
+		if !ok {
+			continue
+		}
+
+		if runTypeCommand(input, out, color) {
+			continue
+		}
+
+		p := parser.New(lexer.New(input))
+
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			printParserErrors(out, p.Errors(), color)
+			continue
+		}
+
+		b, err := json.Marshal(program)
+		if err != nil {
+			fmt.Fprintf(out, "%s\n", err)
+			continue
+		}
+
+		fmt.Fprintf(out, "%s\n", b)
+	}
+}
+
+// 括弧の対応が閉じるまで行を読み、1つの入力としてまとめて返す。
+// 2行目以降は継続プロンプトを表示する。
+// 括弧が閉じないままEOFに達した場合は、それまでに読んだ入力をそのまま返し、
+// 構文解析でエラーとして報告させる。何も読めなかった場合はfalseを返す。
+func readInput(r lineReader, out io.Writer) (string, bool) {
+	var lines []string
+	prompt := PROMPT
+
+	for {
+		line, ok := r.ReadLine(prompt)
+		if !ok {
+			if len(lines) == 0 {
+				return "", false
+			}
+			fmt.Fprintln(out)
+			break
+		}
+
+		lines = append(lines, line)
+		input := strings.Join(lines, "\n")
+		if bracketDepth(input) <= 0 {
+			return input, true
+		}
+
+		prompt = CONTINUATION_PROMPT
+	}
+
+	return strings.Join(lines, "\n"), true
+}
+
+// 入力が「.load <file>」であれば、ファイルの内容を入力として返す。
+// 返した内容は他の入力と同じく字句解析やASTの出力に使われるだけで、定義は環境に束縛されない。
+// ファイルを読み込めなかった場合はエラーを出力してfalseを返す。
+// それ以外の入力はそのまま返す。
+func expandLoadCommand(input string, out io.Writer) (string, bool) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || fields[0] != LOAD_COMMAND {
+		return input, true
+	}
+
+	if len(fields) != 2 {
+		fmt.Fprintf(out, "usage: %s <file>\n", LOAD_COMMAND)
+		return "", false
+	}
+
+	b, err := os.ReadFile(fields[1])
+	if err != nil {
+		fmt.Fprintf(out, "failed to load file: %s\n", err)
+		return "", false
+	}
+
+	return string(b), true
+}
+
+// 入力が「.type <expr>」であれば、式を構文解析して評価結果の型を出力し、trueを返す。
+// 式は評価せずに型を求めるため、型を求められない式についてはその旨を出力する。
+// それ以外の入力に対しては何もせずにfalseを返す。
+func runTypeCommand(input string, out io.Writer, color bool) bool {
+	trimmed := strings.TrimSpace(input)
+	if trimmed != TYPE_COMMAND && !strings.HasPrefix(trimmed, TYPE_COMMAND+" ") {
+		return false
+	}
+
+	// エラーの位置が入力の位置と一致するよう、コマンド名を空白に置き換えて構文解析する。
+	src := strings.Replace(input, TYPE_COMMAND, strings.Repeat(" ", len(TYPE_COMMAND)), 1)
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(out, p.Errors(), color)
+		return true
+	}
+
+	if len(program.Statements) != 1 {
+		fmt.Fprintf(out, "usage: %s <expression>\n", TYPE_COMMAND)
+		return true
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		fmt.Fprintf(out, "usage: %s <expression>\n", TYPE_COMMAND)
+		return true
+	}
+
+	t, ok := staticType(stmt.Expression)
+	if !ok {
+		fmt.Fprintf(out, "cannot determine type of %s without evaluation\n", stmt.Expression.String())
+		return true
+	}
+
+	fmt.Fprintf(out, "%s\n", t)
+	return true
+}
+
+// 入力をトークンに分割し、閉じていない括弧・波括弧・角括弧の数を返す。
+func bracketDepth(input string) int {
+	depth := 0
+	l := lexer.New(input)
+
+	for t := l.NextToken(); t.Type != token.EOF; t = l.NextToken() {
+		switch t.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth++
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth--
+		}
+	}
+
+	return depth
+}
 
-		for t := l.NextToken(); t.Type != token.EOF; t = l.NextToken() {
-			fmt.Printf("%+v\n", t)
+// 構文エラーを1行ずつ出力する。colorがtrueの場合はエラーの色を付ける。
+func printParserErrors(out io.Writer, errors []string, color bool) {
+	for _, msg := range errors {
+		if color {
+			msg = paint(msg, objectColors[object.ERROR_OBJ])
 		}
+		fmt.Fprintf(out, "\t%s\n", msg)
 	}
 }
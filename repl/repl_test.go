@@ -0,0 +1,219 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestStartLexer(t *testing.T) {
+	in := strings.NewReader("let x = 5;\n")
+	var out bytes.Buffer
+
+	StartLexer(in, &out)
+
+	expected := PROMPT +
+		"{Type:LET Literal:let}\n" +
+		"{Type:IDENT Literal:x}\n" +
+		"{Type:= Literal:=}\n" +
+		"{Type:INT Literal:5}\n" +
+		"{Type:; Literal:;}\n" +
+		PROMPT
+
+	if out.String() != expected {
+		t.Errorf("output wrong.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestStartStats(t *testing.T) {
+	in := strings.NewReader("fib(20)\n")
+	var out bytes.Buffer
+
+	StartStats(in, &out)
+
+	lines := strings.Split(out.String(), "\n")
+	expected := []string{
+		PROMPT + "{Type:IDENT Literal:fib}",
+		"{Type:( Literal:(}",
+		"{Type:INT Literal:20}",
+		"{Type:) Literal:)}",
+	}
+	if len(lines) != len(expected)+2 {
+		t.Fatalf("wrong number of lines. got=%q", out.String())
+	}
+	for i, line := range expected {
+		if lines[i] != line {
+			t.Errorf("lines[%d] wrong. expected=%q, got=%q", i, line, lines[i])
+		}
+	}
+
+	// 時間とアロケーションの回数は実行ごとに変わるため、形式だけを確かめる。
+	if !regexp.MustCompile(`^lexer: elapsed: [0-9.]+(ns|µs|ms|s), allocs: [0-9]+$`).MatchString(lines[len(expected)]) {
+		t.Errorf("stats line wrong. got=%q", lines[len(expected)])
+	}
+	if lines[len(expected)+1] != PROMPT {
+		t.Errorf("last line wrong. got=%q", lines[len(expected)+1])
+	}
+
+	// 通常のモードでは統計を出力しない。
+	out.Reset()
+	StartLexer(strings.NewReader("fib(20)\n"), &out)
+	if strings.Contains(out.String(), "elapsed:") {
+		t.Errorf("StartLexer printed stats. got=%q", out.String())
+	}
+}
+
+func TestStartASTJSON(t *testing.T) {
+	in := strings.NewReader("let x = 1 + 2;\nlet = 1;\n")
+	var out bytes.Buffer
+
+	StartASTJSON(in, &out)
+
+	expected := PROMPT +
+		`{"nodeType":"Program","statements":[{"nodeType":"LetStatement",` +
+		`"name":{"nodeType":"Identifier","value":"x"},` +
+		`"value":{"nodeType":"InfixExpression","operator":"+",` +
+		`"left":{"nodeType":"IntegerLiteral","value":1},"right":{"nodeType":"IntegerLiteral","value":2}}}]}` + "\n" +
+		PROMPT + "\t1:5: expected next token to be IDENT, got = instead\n" +
+		PROMPT
+
+	if out.String() != expected {
+		t.Errorf("output wrong.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestStartASTJSONMultiLine(t *testing.T) {
+	in := strings.NewReader("while (x) {\n  x;\n}\n")
+	var out bytes.Buffer
+
+	StartASTJSON(in, &out)
+
+	expected := PROMPT + CONTINUATION_PROMPT + CONTINUATION_PROMPT +
+		`{"nodeType":"Program","statements":[{"nodeType":"WhileStatement",` +
+		`"condition":{"nodeType":"Identifier","value":"x"},` +
+		`"body":{"nodeType":"BlockStatement","statements":[{"nodeType":"ExpressionStatement",` +
+		`"expression":{"nodeType":"Identifier","value":"x"}}]}}]}` + "\n" +
+		PROMPT
+
+	if out.String() != expected {
+		t.Errorf("output wrong.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestStartASTJSONUnclosedAtEOF(t *testing.T) {
+	in := strings.NewReader("let a = [1,\n2")
+	var out bytes.Buffer
+
+	StartASTJSON(in, &out)
+
+	expected := PROMPT + CONTINUATION_PROMPT + CONTINUATION_PROMPT + "\n" +
+		"\t2:2: expected next token to be ], got EOF instead\n" +
+		PROMPT
+
+	if out.String() != expected {
+		t.Errorf("output wrong.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestBracketDepth(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"let x = 1;", 0},
+		{"while (x) {", 1},
+		{"let a = [1, [2,", 2},
+		{"}", -1},
+		{`let s = "{";`, 0},
+		{"x; // {", 0},
+	}
+
+	for _, tt := range tests {
+		if depth := bracketDepth(tt.input); depth != tt.expected {
+			t.Errorf("bracketDepth(%q) wrong. expected=%d, got=%d", tt.input, tt.expected, depth)
+		}
+	}
+}
+
+func TestStartASTJSONLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.monkey")
+	if err := os.WriteFile(path, []byte("let x = 1;\n"), 0644); err != nil {
+		t.Fatalf("os.WriteFile returned error: %s", err)
+	}
+
+	in := strings.NewReader(".load " + path + "\n.load\n.load " + path + ".missing\nx;\n")
+	var out bytes.Buffer
+
+	StartASTJSON(in, &out)
+
+	expected := PROMPT +
+		`{"nodeType":"Program","statements":[{"nodeType":"LetStatement",` +
+		`"name":{"nodeType":"Identifier","value":"x"},"value":{"nodeType":"IntegerLiteral","value":1}}]}` + "\n" +
+		PROMPT + "usage: .load <file>\n" +
+		PROMPT + "failed to load file: open " + path + ".missing: no such file or directory\n" +
+		PROMPT + `{"nodeType":"Program","statements":[{"nodeType":"ExpressionStatement",` +
+		`"expression":{"nodeType":"Identifier","value":"x"}}]}` + "\n" +
+		PROMPT
+
+	if out.String() != expected {
+		t.Errorf("output wrong.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestTypeCommand(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{".type 1 + 2", "INTEGER\n"},
+		{`.type "x"`, "STRING\n"},
+		{`.type "a" + "b"`, "STRING\n"},
+		{".type -5 * ~1", "INTEGER\n"},
+		{".type 1 < 2 && !x", "BOOLEAN\n"},
+		{".type 1 + 1.5", "FLOAT\n"},
+		{".type -2.5 * 2", "FLOAT\n"},
+		{".type 2 == 2.0", "BOOLEAN\n"},
+		{".type 1.5 < 2", "BOOLEAN\n"},
+		{".type 1.5 % 2", "cannot determine type of (1.5 % 2) without evaluation\n"},
+		{".type ~1.5", "cannot determine type of (~1.5) without evaluation\n"},
+		{".type [1, 2][0]", "cannot determine type of ([1, 2][0]) without evaluation\n"},
+		{".type f(1)", "cannot determine type of f(1) without evaluation\n"},
+		{".type fn(x) { x }", "FUNCTION\n"},
+		{".type", "usage: .type <expression>\n"},
+		{".type let x = 1;", "usage: .type <expression>\n"},
+		{".type 1 +", "\t1:10: no prefix parse function for EOF found\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if !runTypeCommand(tt.input, &out, false) {
+			t.Errorf("input %q: not handled as type command", tt.input)
+			continue
+		}
+		if out.String() != tt.expected {
+			t.Errorf("input %q: output wrong. expected=%q, got=%q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	for _, input := range []string{"1 + 2", ".typex 1", "x.type"} {
+		var out bytes.Buffer
+		if runTypeCommand(input, &out, false) {
+			t.Errorf("input %q: handled as type command", input)
+		}
+	}
+}
+
+func TestStartTypeCommand(t *testing.T) {
+	in := strings.NewReader(".type 1 + 2\n")
+	var out bytes.Buffer
+
+	Start(in, &out)
+
+	expected := PROMPT + "INTEGER\n" + PROMPT
+	if out.String() != expected {
+		t.Errorf("output wrong.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
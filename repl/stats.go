@@ -0,0 +1,45 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// 入力の処理にかかった時間とアロケーションの回数
+type stats struct {
+	// 計測した処理の段階の名前
+	phase   string
+	elapsed time.Duration
+	allocs  uint64
+}
+
+func (s stats) String() string {
+	return fmt.Sprintf("%s: elapsed: %s, allocs: %d", s.phase, s.elapsed, s.allocs)
+}
+
+// 段階phaseの処理としてfを実行し、かかった時間とその間のアロケーションの回数を返す。
+// アロケーションの回数はプロセス全体の値の差のため、他のゴルーチンのアロケーションも含む。
+func measure(phase string, f func()) stats {
+	var before, after runtime.MemStats
+
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	f()
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return stats{phase: phase, elapsed: elapsed, allocs: after.Mallocs - before.Mallocs}
+}
+
+// 統計を1行で出力する。colorがtrueの場合は目立たない色を付ける。
+func printStats(out io.Writer, s stats, color bool) {
+	msg := s.String()
+	if color {
+		msg = paint(msg, COLOR_GRAY)
+	}
+	fmt.Fprintf(out, "%s\n", msg)
+}
@@ -0,0 +1,119 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// lineReader プロンプトを表示して入力を1行ずつ読み込む
+type lineReader interface {
+	// ReadLine 入力の終わりに達した場合はfalseを返す。
+	ReadLine(prompt string) (string, bool)
+	// Close 読み込みを終えるときの後始末をする。
+	Close()
+}
+
+// 入出力がともに端末であれば行編集と履歴の使えるlineReaderを、
+// そうでなければ1行ずつ読むだけのlineReaderを返す。
+// 端末では改行の扱いが変わるため、REPLの出力は返されたio.Writerに書き込む。
+func newLineReader(in io.Reader, out io.Writer) (lineReader, io.Writer) {
+	inFile, ok := in.(*os.File)
+	if !ok || !term.IsTerminal(int(inFile.Fd())) {
+		return newScannerReader(in, out), out
+	}
+	outFile, ok := out.(*os.File)
+	if !ok || !term.IsTerminal(int(outFile.Fd())) {
+		return newScannerReader(in, out), out
+	}
+
+	r, err := newTerminalReader(inFile, outFile)
+	if err != nil {
+		return newScannerReader(in, out), out
+	}
+
+	return r, r.terminal
+}
+
+// scannerReader bufio.Scannerで1行ずつ読み込むlineReader
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func newScannerReader(in io.Reader, out io.Writer) *scannerReader {
+	return &scannerReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+func (r *scannerReader) ReadLine(prompt string) (string, bool) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		return "", false
+	}
+
+	return r.scanner.Text(), true
+}
+
+func (r *scannerReader) Close() {}
+
+// terminalReader 端末をrawモードにして、行編集と履歴を使えるようにしたlineReader
+// 履歴は起動時に履歴ファイルから読み込み、終了時に書き戻す。
+type terminalReader struct {
+	terminal    *term.Terminal
+	fd          int
+	state       *term.State
+	history     *History
+	historyPath string
+}
+
+func newTerminalReader(in, out *os.File) (*terminalReader, error) {
+	fd := int(in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &terminalReader{
+		terminal: term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{in, out}, ""),
+		fd:      fd,
+		state:   state,
+		history: &History{},
+	}
+
+	// 履歴ファイルが読めない場合は、履歴を保存せずにセッション中だけ使う。
+	if path, err := HistoryPath(); err == nil {
+		if h, err := LoadHistory(path); err == nil {
+			r.history = h
+			r.historyPath = path
+		}
+	}
+	r.terminal.History = r.history
+
+	return r, nil
+}
+
+func (r *terminalReader) ReadLine(prompt string) (string, bool) {
+	r.terminal.SetPrompt(prompt)
+	line, err := r.terminal.ReadLine()
+	if err != nil {
+		return "", false
+	}
+
+	return line, true
+}
+
+func (r *terminalReader) Close() {
+	term.Restore(r.fd, r.state)
+
+	if r.historyPath != "" {
+		if err := r.history.Save(r.historyPath); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to save history: %s\n", err)
+		}
+	}
+}
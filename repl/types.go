@@ -0,0 +1,112 @@
+package repl
+
+import (
+	"local.packages/ast"
+	"local.packages/object"
+)
+
+// 式を評価せずに、評価結果の型を求めて返す。
+// リテラルと、型がオペランドの型だけで決まる演算子の式に限り、それ以外はfalseを返す。
+// 評価しないため、関数呼び出しのような副作用を持ちうる式の型は求めない。
+func staticType(exp ast.Expression) (object.ObjectType, bool) {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return object.INTEGER_OBJ, true
+	case *ast.FloatLiteral:
+		return object.FLOAT_OBJ, true
+	case *ast.StringLiteral, *ast.TemplateLiteral:
+		return object.STRING_OBJ, true
+	case *ast.Boolean:
+		return object.BOOLEAN_OBJ, true
+	case *ast.NullLiteral:
+		return object.NULL_OBJ, true
+	case *ast.ArrayLiteral:
+		return object.ARRAY_OBJ, true
+	case *ast.HashLiteral:
+		return object.HASH_OBJ, true
+	case *ast.FunctionLiteral:
+		return object.FUNCTION_OBJ, true
+	case *ast.MacroLiteral:
+		return object.MACRO_OBJ, true
+	case *ast.PrefixExpression:
+		return staticPrefixType(exp)
+	case *ast.InfixExpression:
+		return staticInfixType(exp)
+	default:
+		return "", false
+	}
+}
+
+// 前置式の評価結果の型を返す。
+func staticPrefixType(exp *ast.PrefixExpression) (object.ObjectType, bool) {
+	if exp.Operator == "!" {
+		return object.BOOLEAN_OBJ, true
+	}
+
+	right, ok := staticType(exp.Right)
+	if !ok {
+		return "", false
+	}
+
+	switch {
+	case exp.Operator == "-" && (right == object.INTEGER_OBJ || right == object.FLOAT_OBJ):
+		return right, true
+	case exp.Operator == "~" && right == object.INTEGER_OBJ:
+		return object.INTEGER_OBJ, true
+	default:
+		return "", false
+	}
+}
+
+// 中置式の評価結果の型を返す。
+func staticInfixType(exp *ast.InfixExpression) (object.ObjectType, bool) {
+	switch exp.Operator {
+	case "==", "!=":
+		return object.BOOLEAN_OBJ, true
+	}
+
+	left, ok := staticType(exp.Left)
+	if !ok {
+		return "", false
+	}
+	right, ok := staticType(exp.Right)
+	if !ok {
+		return "", false
+	}
+
+	// 整数と浮動小数点数の混在した演算は、整数を浮動小数点数に昇格して計算する。
+	if isNumericType(left) && isNumericType(right) && left != right {
+		left, right = object.FLOAT_OBJ, object.FLOAT_OBJ
+	}
+	if left != right {
+		return "", false
+	}
+
+	switch {
+	case left == object.INTEGER_OBJ:
+		switch exp.Operator {
+		case "+", "-", "*", "/", "%", "&", "|", "^", "<<", ">>":
+			return object.INTEGER_OBJ, true
+		case "<", ">":
+			return object.BOOLEAN_OBJ, true
+		}
+	case left == object.FLOAT_OBJ:
+		switch exp.Operator {
+		case "+", "-", "*", "/":
+			return object.FLOAT_OBJ, true
+		case "<", ">":
+			return object.BOOLEAN_OBJ, true
+		}
+	case left == object.STRING_OBJ && exp.Operator == "+":
+		return object.STRING_OBJ, true
+	case left == object.BOOLEAN_OBJ && (exp.Operator == "&&" || exp.Operator == "||"):
+		return object.BOOLEAN_OBJ, true
+	}
+
+	return "", false
+}
+
+// 整数か浮動小数点数の型であればtrueを返す。
+func isNumericType(t object.ObjectType) bool {
+	return t == object.INTEGER_OBJ || t == object.FLOAT_OBJ
+}
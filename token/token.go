@@ -7,17 +7,36 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	// トークンの開始位置の行番号（1始まり）。位置情報が無い場合は0。
+	Line int
+	// トークンの開始位置の列番号（1始まり）。位置情報が無い場合は0。
+	Column int
+	// トークンの開始位置の、入力の先頭からのバイト数（0始まり）。
+	Offset int
 }
 
 // 予約語とそのTokenTypeへのマッピング
+// 複数のLexerから並行して参照されるため、読み取りだけを行う。
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"const":    CONST,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"while":    WHILE,
+	"for":      FOR,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"null":     NULL,
+	"macro":    MACRO,
+	"switch":   SWITCH,
+	"case":     CASE,
+	"default":  DEFAULT,
+	"try":      TRY,
+	"catch":    CATCH,
 }
 
 // 定数定義のブロック
@@ -28,12 +47,26 @@ const (
 	// EOF End of File
 	EOF = "EOF"
 
+	// WHITESPACE 空白文字の並び。Lexer.AllTokensでのみ生成する。
+	WHITESPACE = "WHITESPACE"
+	// COMMENT 行コメントとブロックコメント。Lexer.AllTokensでのみ生成する。
+	COMMENT = "COMMENT"
+
 	// IDENT 識別子
 	IDENT = "IDENT"
 
 	// INT リテラル
 	INT = "INT"
 
+	// FLOAT 浮動小数点数リテラル
+	FLOAT = "FLOAT"
+
+	// STRING 文字列リテラル
+	STRING = "STRING"
+
+	// TEMPLATE_STRING テンプレート文字列のうち、埋め込み式以外の部分
+	TEMPLATE_STRING = "TEMPLATE_STRING"
+
 	// 演算子
 	ASSIGN   = "="
 	PLUS     = "+"
@@ -41,28 +74,66 @@ const (
 	BANG     = "!"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
 	LT       = "<"
 	GT       = ">"
 	EQ       = "=="
 	NOT_EQ   = "!="
+	AND      = "&&"
+	OR       = "||"
+
+	// ビット演算子
+	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	TILDE     = "~"
+	LSHIFT    = "<<"
+	RSHIFT    = ">>"
+
+	// 複合代入演算子
+	PLUS_EQ     = "+="
+	MINUS_EQ    = "-="
+	ASTERISK_EQ = "*="
+	SLASH_EQ    = "/="
 
 	// デリミタ
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
+	ELLIPSIS  = "..."
+
+	// テンプレート文字列
+	BACKTICK      = "`"
+	DOLLAR_LBRACE = "${"
 
 	LPAREN = "("
 	RPAREN = ")"
 	LBRACE = "{"
 	RBRACE = "}"
 
+	LBRACKET = "["
+	RBRACKET = "]"
+
 	// キーワード
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
+	CONST    = "CONST"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	NULL     = "NULL"
+	MACRO    = "MACRO"
+	SWITCH   = "SWITCH"
+	CASE     = "CASE"
+	DEFAULT  = "DEFAULT"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
 )
 
 // LookupIdentifier 識別子が予約語にマッチしたら予約語に対応するTokenTypeを、